@@ -0,0 +1,203 @@
+package benchmarks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	logrus_bugsnag "github.com/vend/logrus-bugsnag"
+)
+
+const (
+	baselineEnv = "LOGRUS_BUGSNAG_BENCH_BASELINE"
+	factorEnv   = "LOGRUS_BUGSNAG_BENCH_FACTOR"
+
+	baselinesFile = "testdata/baselines.json"
+)
+
+var update = flag.Bool("update", false, "update "+baselinesFile+" with the measured values")
+
+const apiKey = "12345678901234567890123456789012"
+
+// nopNotifier discards the events sent by the hook.
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(err error, rawData ...interface{}) error {
+	return nil
+}
+
+// nopTransport answers every request without network I/O.
+type nopTransport struct{}
+
+func (nopTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+// newLogger returns a logger with a hook created with the given options,
+// sending to a nopNotifier.
+func newLogger(b *testing.B, opts ...logrus_bugsnag.Option) *logrus.Logger {
+	hook, err := logrus_bugsnag.NewBugsnagHook(append([]logrus_bugsnag.Option{
+		logrus_bugsnag.WithRegistry(nil),
+		logrus_bugsnag.WithNotifier(nopNotifier{}),
+	}, opts...)...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+	return log
+}
+
+func BenchmarkErrorWithFields(b *testing.B) {
+	log := newLogger(b)
+	err := errors.New("order failed")
+	fields := logrus.Fields{"order_id": 42, "customer": "walrus", "token": "secret"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.WithFields(fields).WithError(err).Error("checkout")
+	}
+}
+
+func BenchmarkMessageOnly(b *testing.B) {
+	log := newLogger(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Error("order failed")
+	}
+}
+
+// BenchmarkSampledOut measures entries dropped by the rate limit, the hook
+// having no sampling.
+func BenchmarkSampledOut(b *testing.B) {
+	log := newLogger(b, logrus_bugsnag.WithRateLimit(rate.Every(time.Hour), 1))
+	log.Error("order failed")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Error("order failed")
+	}
+}
+
+func BenchmarkSuppressedCancellation(b *testing.B) {
+	log := newLogger(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.WithError(context.Canceled).Error("request canceled")
+	}
+}
+
+func BenchmarkAsyncEnqueue(b *testing.B) {
+	notifier := bugsnag.New(bugsnag.Configuration{
+		APIKey:    apiKey,
+		Endpoints: bugsnag.Endpoints{Notify: "http://localhost/", Sessions: "http://localhost/"},
+		Transport: nopTransport{},
+	})
+	hook, err := logrus_bugsnag.NewBugsnagHookWithNotifier(notifier, logrus_bugsnag.WithRegistry(nil))
+	if err != nil {
+		b.Fatal(err)
+	}
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Error("order failed")
+	}
+	b.StopTimer()
+	if err := hook.Flush(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+}
+
+var benchmarks = map[string]func(*testing.B){
+	"ErrorWithFields":        BenchmarkErrorWithFields,
+	"MessageOnly":            BenchmarkMessageOnly,
+	"SampledOut":             BenchmarkSampledOut,
+	"SuppressedCancellation": BenchmarkSuppressedCancellation,
+	"AsyncEnqueue":           BenchmarkAsyncEnqueue,
+}
+
+// baseline is the committed cost of a benchmark.
+type baseline struct {
+	NsPerOp     int64 `json:"ns_per_op"`
+	AllocsPerOp int64 `json:"allocs_per_op"`
+}
+
+func TestBaselines(t *testing.T) {
+	if os.Getenv(baselineEnv) == "" {
+		t.Skipf("set %s to compare the benchmarks with their baselines", baselineEnv)
+	}
+	factor := 1.5
+	if s := os.Getenv(factorEnv); s != "" {
+		var err error
+		if factor, err = strconv.ParseFloat(s, 64); err != nil {
+			t.Fatalf("invalid %s: %v", factorEnv, err)
+		}
+	}
+
+	baselines := map[string]baseline{}
+	data, err := ioutil.ReadFile(baselinesFile)
+	if err != nil && !(*update && os.IsNotExist(err)) {
+		t.Fatal(err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &baselines); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, bench := range benchmarks {
+		result := testing.Benchmark(bench)
+		measured := baseline{NsPerOp: result.NsPerOp(), AllocsPerOp: result.AllocsPerOp()}
+		if *update {
+			baselines[name] = measured
+			continue
+		}
+		want, ok := baselines[name]
+		if !ok {
+			t.Errorf("%s: no baseline", name)
+			continue
+		}
+		if float64(measured.NsPerOp) > factor*float64(want.NsPerOp) {
+			t.Errorf("%s: %d ns/op exceeds the baseline of %d ns/op by more than %gx", name, measured.NsPerOp, want.NsPerOp, factor)
+		}
+		if float64(measured.AllocsPerOp) > factor*float64(want.AllocsPerOp) {
+			t.Errorf("%s: %d allocs/op exceeds the baseline of %d allocs/op by more than %gx", name, measured.AllocsPerOp, want.AllocsPerOp, factor)
+		}
+	}
+
+	if *update {
+		data, err := json.MarshalIndent(baselines, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(baselinesFile, append(data, '\n'), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
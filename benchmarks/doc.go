@@ -0,0 +1,14 @@
+// Package benchmarks measures the cost of a logrus_bugsnag hook on the paths
+// taken by common entries, without network I/O.
+//
+// Run the benchmarks with:
+//
+//	go test -run '^$' -bench . -benchmem ./benchmarks
+//
+// With LOGRUS_BUGSNAG_BENCH_BASELINE set, TestBaselines fails if a benchmark
+// exceeds its baseline in testdata/baselines.json by more than the factor set
+// in LOGRUS_BUGSNAG_BENCH_FACTOR, 1.5 by default. Update the baselines
+// deliberately, after a change known to affect performance, with:
+//
+//	LOGRUS_BUGSNAG_BENCH_BASELINE=1 go test -run TestBaselines ./benchmarks -update
+package benchmarks
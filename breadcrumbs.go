@@ -0,0 +1,105 @@
+package logrus_bugsnag
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const breadcrumbsTab = "breadcrumbs"
+
+// BreadcrumbRecorder is a logrus hook keeping the most recent entries of a
+// logger, to be attached to the events of a Bugsnag hook created with
+// WithBreadcrumbs. It is safe for concurrent use. Entries from all goroutines
+// are recorded in the same buffer.
+type BreadcrumbRecorder struct {
+	levels []logrus.Level
+	fields []string
+
+	mu      sync.Mutex
+	crumbs  []breadcrumb
+	next    int
+	wrapped bool
+}
+
+type breadcrumb struct {
+	time    time.Time
+	level   logrus.Level
+	message string
+	fields  map[string]interface{}
+}
+
+// NewBreadcrumbRecorder returns a recorder keeping the last size entries at
+// the given levels, along with their fields named in fields. Add it to a
+// logger with AddHook.
+func NewBreadcrumbRecorder(size int, levels []logrus.Level, fields ...string) *BreadcrumbRecorder {
+	if size < 1 {
+		size = 1
+	}
+	return &BreadcrumbRecorder{
+		levels: levels,
+		fields: fields,
+		crumbs: make([]breadcrumb, size),
+	}
+}
+
+// Levels returns the levels of the entries recorded.
+func (r *BreadcrumbRecorder) Levels() []logrus.Level {
+	return r.levels
+}
+
+// Fire records the entry, replacing the oldest one if the buffer is full.
+func (r *BreadcrumbRecorder) Fire(entry *logrus.Entry) error {
+	crumb := breadcrumb{time: entry.Time, level: entry.Level, message: entry.Message}
+	for _, key := range r.fields {
+		if val, ok := entry.Data[key]; ok {
+			if crumb.fields == nil {
+				crumb.fields = make(map[string]interface{}, len(r.fields))
+			}
+			crumb.fields[key] = val
+		}
+	}
+
+	r.mu.Lock()
+	r.crumbs[r.next] = crumb
+	r.next++
+	if r.next == len(r.crumbs) {
+		r.next = 0
+		r.wrapped = true
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// recent returns the recorded entries, oldest first.
+func (r *BreadcrumbRecorder) recent() []breadcrumb {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.wrapped {
+		return append([]breadcrumb(nil), r.crumbs[:r.next]...)
+	}
+	return append(append([]breadcrumb(nil), r.crumbs[r.next:]...), r.crumbs[:r.next]...)
+}
+
+// breadcrumbsTab returns the "breadcrumbs" tab listing the recorded entries,
+// oldest first, or nil if there are none.
+func (hook *bugsnagHook) breadcrumbsTab() map[string]interface{} {
+	crumbs := hook.breadcrumbs.recent()
+	if len(crumbs) == 0 {
+		return nil
+	}
+	entries := make([]interface{}, len(crumbs))
+	for i, crumb := range crumbs {
+		entry := map[string]interface{}{
+			"timestamp": crumb.time.UTC().Format(time.RFC3339Nano),
+			"level":     crumb.level.String(),
+			"message":   crumb.message,
+		}
+		if crumb.fields != nil {
+			entry["fields"] = hook.redactor.redactMap(crumb.fields)
+		}
+		entries[i] = entry
+	}
+	return map[string]interface{}{"entries": entries}
+}
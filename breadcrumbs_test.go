@@ -0,0 +1,68 @@
+package logrus_bugsnag
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreadcrumbs(t *testing.T) {
+	recorder := NewBreadcrumbRecorder(3, []logrus.Level{logrus.InfoLevel, logrus.DebugLevel}, "order_id", "token")
+	log, _, c, teardown := newTestLogger(t, WithBreadcrumbs(recorder))
+	defer teardown()
+	log.SetLevel(logrus.DebugLevel)
+	log.AddHook(recorder)
+
+	log.Info("starting")
+	for i := 1; i <= 3; i++ {
+		log.WithFields(logrus.Fields{"order_id": i, "token": "secret", "other": true}).Debug(fmt.Sprintf("processing order %d", i))
+	}
+	log.Warn("not recorded")
+	log.Error("failed")
+
+	event := receiveEvent(t, c)
+	entries, ok := event.Metadata["breadcrumbs"]["entries"].([]interface{})
+	require.True(t, ok, "no breadcrumbs")
+	require.Len(t, entries, 3)
+	for i, entry := range entries {
+		crumb := entry.(map[string]interface{})
+		assert.Equal(t, fmt.Sprintf("processing order %d", i+1), crumb["message"])
+		assert.Equal(t, "debug", crumb["level"])
+		assert.NotEmpty(t, crumb["timestamp"])
+		assert.Equal(t, map[string]interface{}{"order_id": float64(i + 1), "token": "[REDACTED]"}, crumb["fields"])
+	}
+}
+
+func TestBreadcrumbsEmpty(t *testing.T) {
+	recorder := NewBreadcrumbRecorder(3, []logrus.Level{logrus.InfoLevel})
+	log, _, notifier := newFakeLogger(t, WithBreadcrumbs(recorder))
+	log.Error("first")
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	assert.NotContains(t, calls[0].metadata(), "breadcrumbs")
+}
+
+func TestBreadcrumbRecorderConcurrent(t *testing.T) {
+	recorder := NewBreadcrumbRecorder(10, []logrus.Level{logrus.InfoLevel})
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.AddHook(recorder)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				log.Info("working")
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, recorder.recent(), 10)
+}
@@ -0,0 +1,107 @@
+package logrus_bugsnag
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of the circuit breaker of a hook.
+type CircuitState int
+
+const (
+	// CircuitClosed lets events be delivered.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen drops events until the cool-down period has elapsed.
+	CircuitOpen
+	// CircuitHalfOpen lets a single event be delivered to probe the
+	// endpoint, and drops the others until its outcome is known.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker stops deliveries after consecutive failures.
+type circuitBreaker struct {
+	threshold int
+	window    time.Duration
+	coolDown  time.Duration
+
+	mu           sync.Mutex
+	state        CircuitState
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+}
+
+// allow reports whether an event may be delivered. Its outcome must then be
+// passed to record.
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) < b.coolDown {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		return true
+	case CircuitHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+// record updates the state with the outcome of a delivery.
+func (b *circuitBreaker) record(err error, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		b.state = CircuitClosed
+		b.failures = 0
+		return
+	}
+	if b.state != CircuitClosed {
+		b.open(now)
+		return
+	}
+	if b.failures == 0 || now.Sub(b.firstFailure) > b.window {
+		b.failures = 0
+		b.firstFailure = now
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.open(now)
+	}
+}
+
+// release lets another event probe the endpoint if the delivery allowed to
+// probe it was abandoned.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitHalfOpen {
+		b.state = CircuitOpen
+	}
+}
+
+func (b *circuitBreaker) open(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+	b.failures = 0
+}
+
+func (b *circuitBreaker) currentState() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
@@ -0,0 +1,73 @@
+package logrus_bugsnag
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	var requests, working int32
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&working) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		c.ServeHTTP(w, r)
+	}))
+	defer endpoint.Close()
+
+	log, hook := newRecordingLogger(t, endpoint.URL, WithCircuitBreaker(3, time.Minute, 100*time.Millisecond))
+	for i := 0; i < 5; i++ {
+		log.Error("outage")
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	assert.Equal(t, CircuitOpen, hook.Stats().Circuit)
+	assert.Equal(t, int64(2), hook.Stats().Dropped)
+
+	// The endpoint recovers, but the breaker waits for the cool-down.
+	atomic.StoreInt32(&working, 1)
+	log.Error("cooling down")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	time.Sleep(100 * time.Millisecond)
+	log.Error("probe")
+	assert.Equal(t, "probe", receiveEvent(t, c).Exceptions[0].Message)
+	assert.Equal(t, CircuitClosed, hook.Stats().Circuit)
+	log.Error("recovered")
+	assert.Equal(t, "recovered", receiveEvent(t, c).Exceptions[0].Message)
+	assert.Equal(t, Stats{Attempted: 8, Sent: 2, Dropped: 3, Failed: 3}, counters(hook.Stats()))
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	b := &circuitBreaker{threshold: 2, window: time.Minute, coolDown: time.Second}
+	now := time.Now()
+
+	// Failures further apart than the window are not consecutive.
+	b.record(assert.AnError, now)
+	b.record(assert.AnError, now.Add(2*time.Minute))
+	assert.Equal(t, CircuitClosed, b.currentState())
+	b.record(assert.AnError, now.Add(2*time.Minute+time.Second))
+	assert.Equal(t, CircuitOpen, b.currentState())
+
+	now = now.Add(2*time.Minute + time.Second)
+	assert.False(t, b.allow(now))
+	now = now.Add(time.Second)
+	assert.True(t, b.allow(now))
+	assert.Equal(t, CircuitHalfOpen, b.currentState())
+	assert.False(t, b.allow(now), "only one probe is allowed")
+
+	// A failed probe opens the breaker again.
+	b.record(assert.AnError, now)
+	assert.Equal(t, CircuitOpen, b.currentState())
+	assert.False(t, b.allow(now.Add(time.Second/2)))
+	assert.Equal(t, "open", b.currentState().String())
+}
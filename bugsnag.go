@@ -3,15 +3,206 @@ package logrus_bugsnag
 import (
 	"context"
 	"errors"
-	"net/url"
+	"fmt"
+	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	bugsnag "github.com/bugsnag/bugsnag-go"
 	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
 	"github.com/sirupsen/logrus"
 )
 
-type bugsnagHook struct{}
+type bugsnagHook struct {
+	// mu guards the fields below that can be changed while the hook is in
+	// use.
+	mu              sync.RWMutex
+	notifier        *bugsnag.Notifier
+	runbookResolver RunbookResolver
+	closed          bool
+	disabled        bool
+	// base is notifier without the transport set by wrapNotifier.
+	base *bugsnag.Notifier
+	// failure, if set, is the failure of the hook's background deliveries
+	// that made it degrade to synchronous deliveries with base.
+	failure error
+
+	// name identifies the hook in the errors of its registry.
+	name     string
+	registry *Registry
+	pending  pendingTracker
+	// logger, if set, is the logger the hook adds itself to, and removes
+	// itself from when closed.
+	logger *logrus.Logger
+	// parent, if set, is the hook this hook was derived from with
+	// NewDerivedHook, whose notifier sends the events of this hook.
+	parent *bugsnagHook
+
+	runbookURL func(error) string
+
+	// ownNotifier is set if notifier was given by the caller rather than
+	// built from the global configuration, or if sender replaces it.
+	ownNotifier bool
+	// sender, if set, sends events instead of notifier.
+	sender Notifier
+
+	// deferConfigCheck allows notifier to be nil until bugsnag is
+	// configured. reportUnconfigured makes Fire return
+	// ErrBugsnagUnconfigured meanwhile.
+	deferConfigCheck   bool
+	reportUnconfigured bool
+
+	levels []logrus.Level
+	// forceField enables ForceField.
+	forceField bool
+
+	// created is when the hook was created. Entries are ignored until
+	// initSuppression has elapsed since.
+	created         time.Time
+	initSuppression time.Duration
+
+	// skipPackages are skipped at the top of stack traces: the logging
+	// packages and those added with WithSkipPackages.
+	skipPackages []string
+	// maxStackDepth, if set, is the maximum number of stack frames sent.
+	maxStackDepth int
+
+	minimalMatcher  func(*logrus.Entry) bool
+	minimalMetadata bugsnag.MetaData
+
+	redactor redactor
+
+	// mirror, if set, receives a copy of every event sent to notifier.
+	mirror *bugsnag.Notifier
+
+	// compress and batch configure the transport of notifier.
+	compress bool
+	batch    *batcher
+
+	errorChain     bool
+	promoteTabs    bool
+	normalizeUUIDs bool
+	normalizeIPs   bool
+	coercion       *integerCoercion
+	titleCase      bool
+	// textMarshalerErrors sends the text form of the errors implementing
+	// encoding.TextMarshaler as their message.
+	textMarshalerErrors bool
+	// typedFields sends the struct and json.Marshaler fields as decoded from
+	// their JSON encoding.
+	typedFields bool
+	// dropNilFields leaves out the fields holding nil.
+	dropNilFields bool
+	// errorChainTypes are the types of the errors of the chain added to the
+	// "error_chain_types" tab.
+	errorChainTypes []reflect.Type
+	// shortErrorClass strips the package names from the reported error
+	// classes, and errorClassPrefix is prepended to them.
+	shortErrorClass  bool
+	errorClassPrefix string
+	// logMessageField, if set, is the field holding entry.Message in the
+	// "metadata" tab.
+	logMessageField string
+	// timestampField, if set, is the field holding entry.Time in the
+	// "metadata" tab.
+	timestampField string
+	allowedFields  map[string]struct{}
+	// fingerprintFields are the fields whose values set the grouping hash.
+	fingerprintFields []string
+	// featureFlagPrefix prefixes the fields holding feature flags. Feature
+	// flags are disabled if it is empty.
+	featureFlagPrefix string
+	// requestIDHeader, if set, is the canonical name of the HTTP header
+	// holding the request ID stored by RequestIDMiddleware.
+	requestIDHeader string
+	// releaseStageField and appVersionField, if set, are the fields
+	// overriding the release stage and the app version of each event.
+	releaseStageField string
+	appVersionField   string
+	// notifyReleaseStages, if not nil, are the release stages notified,
+	// overriding those of the configuration.
+	notifyReleaseStages []string
+	// panicValueField, if set, is the field holding the panic value of
+	// PanicLevel entries.
+	panicValueField string
+	// notifyTimeout, if set, bounds synchronous deliveries.
+	notifyTimeout time.Duration
+	// ignoredErrors and ignoredErrorTypes are the errors, and the types of
+	// the errors, that are not reported.
+	ignoredErrors     []error
+	ignoredErrorTypes []reflect.Type
+	// ignoreFuncs are the functions set with WithIgnoreErrorsFunc.
+	ignoreFuncs []func(*logrus.Entry, error) bool
+	// ignorableMatchers match the errors that are not reported, starting
+	// with context cancellations.
+	ignorableMatchers []func(error) bool
+	// unhandledLevels are the levels of the entries reported as unhandled
+	// errors.
+	unhandledLevels []logrus.Level
+	// suppressedStatusCodes are the status codes of the HTTP client
+	// responses whose errors are not reported.
+	suppressedStatusCodes map[int]struct{}
+	// multiErrorMode selects how multi-errors are reported, and
+	// maxMultiErrors how many of their errors are reported.
+	multiErrorMode MultiErrorMode
+	maxMultiErrors int
+
+	// profiles are the profiles of the release stages, and profile the
+	// release stage whose profile was applied.
+	profiles map[string]Profile
+	profile  string
+
+	// approve, if set, decides whether each entry is reported.
+	approve func(context.Context, error, *logrus.Entry) bool
+	// sampler, if set, decides which events are sent.
+	sampler *sampler
+	// suppressFeature, if set, reports whether an entry concerns a disabled
+	// feature and must not be reported.
+	suppressFeature func(*logrus.Entry) bool
+	// beforeNotify, if set, is called for each event sent by a bugsnag
+	// notifier.
+	beforeNotify func(*bugsnag.Event, *logrus.Entry) error
+
+	// staticTabs are added to every event, such as the "process" tab.
+	staticTabs  bugsnag.MetaData
+	breadcrumbs *BreadcrumbRecorder
+	plugins     []MetadataPlugin
+
+	limiter          *limiter
+	distributedDedup *distributedDedup
+
+	retry       retryPolicy
+	breaker     *circuitBreaker
+	quarantine  *quarantine
+	stats       hookStats
+	onSendError func(error, *logrus.Entry)
+	// silentFailures makes Fire return nil when a delivery fails.
+	silentFailures bool
+	// spill, if set, keeps the undelivered events to write them to disk when
+	// the hook is closed.
+	spill *spill
+	// errorChannel, if set, receives the errors of failed deliveries.
+	errorChannel chan<- error
+	// tagPrefix, if set, is the prefix of the entry fields holding tags.
+	tagPrefix string
+	// limits cap the metadata of events.
+	limits metadataLimits
+	// tabNameSanitizer, if set, returns the name of the tab promoted from a
+	// field.
+	tabNameSanitizer func(string) string
+	// messageErrorExtractor, if set, finds the reported error in the message
+	// of entries without an error field.
+	messageErrorExtractor func(string) (error, string)
+	// combineMessages prefixes the message of reported errors with the
+	// message of their entry.
+	combineMessages bool
+	// unwrapSQLNulls sends the values of the nullable types of database/sql
+	// rather than their structs.
+	unwrapSQLNulls bool
+	// dryRun logs the events instead of sending them.
+	dryRun bool
+}
 
 // ErrBugsnagUnconfigured is returned if NewBugsnagHook is called before
 // bugsnag.Configure. Bugsnag must be configured before the hook.
@@ -28,69 +219,411 @@ func (e ErrBugsnagSendFailed) Error() string {
 	return "failed to send error to Bugsnag: " + e.err.Error()
 }
 
+// Unwrap returns the error returned by the notifier.
+func (e ErrBugsnagSendFailed) Unwrap() error {
+	return e.err
+}
+
 // NewBugsnagHook initializes a logrus hook which sends exceptions to an
 // exception-tracking service compatible with the Bugsnag API. Before using
 // this hook, you must call bugsnag.Configure(). The returned object should be
 // registered with a log via `AddHook()`
 //
 // Entries that trigger an Error, Fatal or Panic should now include an "error"
-// field to send to Bugsnag.
-func NewBugsnagHook() (*bugsnagHook, error) {
-	if bugsnag.Config.APIKey == "" {
+// field to send to Bugsnag. Optional behaviour can be enabled by passing
+// Options.
+//
+// The hook takes a snapshot of the global bugsnag configuration when it is
+// created and never reads bugsnag.Config afterwards, so calling
+// bugsnag.Configure elsewhere has no effect on the hook until RefreshConfig is
+// called.
+//
+// With WithDeferredConfigCheck, the hook can be created before bugsnag is
+// configured.
+//
+// The hook is safe for concurrent use: Fire may be called from any number of
+// goroutines, as the state of the hook is guarded by mutexes or updated
+// atomically. Fire never modifies the fields of the entry, and copies the
+// maps and slices they hold, so that the caller may modify them once Fire
+// returns, even if the event is delivered later.
+func NewBugsnagHook(opts ...Option) (*bugsnagHook, error) {
+	hook, err := newBugsnagHook(nil, false, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := hook.RefreshConfig(); err != nil && !hook.deferConfigCheck {
+		return nil, err
+	}
+	hook.install()
+	return hook, nil
+}
+
+// NewBugsnagHookWithNotifier initializes a logrus hook like NewBugsnagHook,
+// but sends exceptions with the given notifier instead of the global bugsnag
+// configuration. This allows several hooks reporting to different Bugsnag
+// projects to be used in the same process. The notifier must have an API key,
+// even with WithDeferredConfigCheck.
+func NewBugsnagHookWithNotifier(n *bugsnag.Notifier, opts ...Option) (*bugsnagHook, error) {
+	if n.Config == nil || n.Config.APIKey == "" {
 		return nil, ErrBugsnagUnconfigured
 	}
-	return &bugsnagHook{}, nil
+	hook, err := newBugsnagHook(n, true, opts)
+	if err != nil {
+		return nil, err
+	}
+	hook.install()
+	return hook, nil
+}
+
+// NewBugsnagHookWithWarnings initializes a logrus hook like NewBugsnagHook
+// that also reports entries at the "Warn" level, with the Bugsnag severity
+// "warning".
+func NewBugsnagHookWithWarnings(opts ...Option) (*bugsnagHook, error) {
+	return NewBugsnagHook(append([]Option{withWarnings}, opts...)...)
+}
+
+// NewBugsnagHookMust initializes a logrus hook like NewBugsnagHook, but
+// panics if the hook cannot be created, for use in init functions or in
+// TestMain.
+func NewBugsnagHookMust(opts ...Option) *bugsnagHook {
+	hook, err := NewBugsnagHook(opts...)
+	if err != nil {
+		panic(fmt.Sprintf("logrus_bugsnag: cannot create the hook: %v", err))
+	}
+	return hook
+}
+
+// withWarnings adds the "Warn" level to the levels reported by the hook.
+func withWarnings(hook *bugsnagHook) {
+	hook.levels = append([]logrus.Level{logrus.WarnLevel}, defaultLevels...)
+}
+
+func newBugsnagHook(notifier *bugsnag.Notifier, ownNotifier bool, opts []Option) (*bugsnagHook, error) {
+	hook := defaultHook(notifier, ownNotifier)
+	// The profile is applied first, so that the options override it, and
+	// each option is applied once.
+	for _, opt := range opts {
+		if isStageProfiles(opt) {
+			opt(hook)
+		}
+	}
+	if profile, ok := hook.profiles[releaseStage(notifier)]; ok {
+		for _, opt := range profile {
+			opt(hook)
+		}
+		hook.profile = releaseStage(notifier)
+	}
+	for _, opt := range opts {
+		if !isStageProfiles(opt) {
+			opt(hook)
+		}
+	}
+	if hook.name == "" {
+		hook.name = defaultHookName()
+	}
+	if notifier != nil {
+		hook.base = notifier
+		hook.notifier = hook.wrapNotifier(notifier)
+	}
+	return hook, nil
+}
+
+// defaultHook returns a hook with the default options.
+func defaultHook(notifier *bugsnag.Notifier, ownNotifier bool) *bugsnagHook {
+	return &bugsnagHook{
+		created:      time.Now(),
+		notifier:     notifier,
+		ownNotifier:  ownNotifier,
+		registry:     DefaultRegistry,
+		levels:       defaultLevels,
+		skipPackages: append([]string(nil), defaultSkipPackages...),
+		redactor:     newRedactor(),
+
+		normalizeIPs: true,
+
+		ignorableMatchers: []func(error) bool{isContextCanceled},
+		unhandledLevels:   defaultUnhandledLevels,
+		limits:            defaultMetadataLimits,
+		tabNameSanitizer:  sanitizeTabName,
+
+		featureFlagPrefix: defaultFeatureFlagPrefix,
+	}
+}
+
+// install registers a newly created hook, and adds it to its logger.
+func (hook *bugsnagHook) install() {
+	if hook.registry != nil {
+		hook.registry.add(hook)
+	}
+	if hook.logger != nil {
+		hook.logger.AddHook(hook)
+	}
+	registerBeforeNotify()
+}
+
+// RefreshConfig replaces the hook's configuration snapshot with the current
+// global bugsnag configuration. Call it after a deliberate bugsnag.Configure
+// for the new settings to apply to this hook. RefreshConfig must not run
+// concurrently with bugsnag.Configure. If bugsnag is not configured, the
+// existing snapshot is kept and ErrBugsnagUnconfigured is returned.
+//
+// RefreshConfig also restores the normal operation of a hook degraded by a
+// failure, which is all it does for hooks created with
+// NewBugsnagHookWithNotifier. For a hook created with NewDerivedHook, it
+// restores the hook and refreshes the configuration of its parent.
+func (hook *bugsnagHook) RefreshConfig() error {
+	if hook.parent != nil {
+		hook.restore()
+		return hook.parent.RefreshConfig()
+	}
+	if hook.ownNotifier {
+		hook.restore()
+		return nil
+	}
+	if bugsnag.Config.APIKey == "" {
+		return ErrBugsnagUnconfigured
+	}
+	// bugsnag.New clones the global configuration into the notifier.
+	base := bugsnag.New()
+	notifier := hook.wrapNotifier(base)
+
+	hook.mu.Lock()
+	hook.base = base
+	hook.notifier = notifier
+	hook.failure = nil
+	hook.mu.Unlock()
+	return nil
+}
+
+// SetEnabled enables or disables the hook. A disabled hook stays installed
+// but sends nothing to Bugsnag. It is safe to call while the hook is in use.
+func (hook *bugsnagHook) SetEnabled(enabled bool) {
+	hook.mu.Lock()
+	hook.disabled = !enabled
+	hook.mu.Unlock()
+}
+
+// isActive reports whether the hook is enabled and not shut down.
+func (hook *bugsnagHook) isActive() bool {
+	hook.mu.RLock()
+	defer hook.mu.RUnlock()
+	return !hook.closed && !hook.disabled
+}
+
+// initializing reports whether entries are still ignored after the hook was
+// created, as configured with WithInitializationSuppression.
+func (hook *bugsnagHook) initializing() bool {
+	return hook.initSuppression > 0 && time.Since(hook.created) < hook.initSuppression
 }
 
 // Fire forwards an error to Bugsnag. Given a logrus.Entry, it extracts the
 // "error" field (or the Message if the error isn't present) and sends it off.
 func (hook *bugsnagHook) Fire(entry *logrus.Entry) error {
-	var notifyErr error
-	err, ok := entry.Data["error"].(error)
-	if ok {
-		if isContextCanceled(err) {
+	return hook.FireWithContext(context.Background(), entry)
+}
+
+// FireWithContext forwards an error to Bugsnag like Fire. The synchronous
+// deliveries of a bugsnag notifier are bound by ctx, as well as by the
+// context of the entry: if ctx is done before the delivery completes, the
+// delivery is abandoned and FireWithContext returns ctx.Err(), so that
+// shutting down is not delayed by Bugsnag.
+func (hook *bugsnagHook) FireWithContext(ctx context.Context, entry *logrus.Entry) error {
+	if entries := hook.splitMultiError(entry); entries != nil {
+		var err error
+		for _, entry := range entries {
+			if fireErr := hook.fire(ctx, entry); fireErr != nil {
+				err = fireErr
+			}
+		}
+		return err
+	}
+	return hook.fire(ctx, entry)
+}
+
+// fire forwards an error to Bugsnag like FireWithContext, without splitting
+// multi-errors.
+func (hook *bugsnagHook) fire(ctx context.Context, entry *logrus.Entry) error {
+	if hook.forceField && !hook.reportsLevel(entry.Level) && !fieldSet(entry, ForceField) {
+		return nil
+	}
+	hook.stats.attempted.Add(1)
+	if !hook.isActive() || hook.initializing() || fieldSet(entry, SkipField) {
+		hook.stats.ignored.Add(1)
+		return nil
+	}
+	if err := hook.checkConfigured(); err != nil {
+		hook.stats.ignored.Add(1)
+		if hook.reportUnconfigured {
+			return err
+		}
+		return nil
+	}
+
+	event, outcome := hook.finalize(entry, true)
+	switch outcome {
+	case eventIgnored:
+		hook.stats.ignored.Add(1)
+		return nil
+	case eventDropped:
+		hook.stats.dropped.Add(1)
+		return nil
+	}
+	hook.stats.payload.record(event.Metadata(), event.tabSizes)
+	if hook.dryRun {
+		hook.stats.ignored.Add(1)
+		hook.logDryRun(event)
+		return nil
+	}
+	err := hook.notify(ctx, entry, event.Error, event.RawData)
+	if hook.silentFailures && errors.As(err, &ErrBugsnagSendFailed{}) {
+		return nil
+	}
+	return err
+}
+
+// checkConfigured takes the configuration snapshot of a hook created before
+// bugsnag was configured, if bugsnag has been configured since. It returns
+// ErrBugsnagUnconfigured if it still isn't.
+func (hook *bugsnagHook) checkConfigured() error {
+	if notifier, _ := hook.notifiers(); notifier != nil {
+		return nil
+	}
+	return hook.RefreshConfig()
+}
+
+// notifiers returns the notifier of the hook and its base, which are those of
+// its parent for a derived hook.
+func (hook *bugsnagHook) notifiers() (notifier, base *bugsnag.Notifier) {
+	if hook.parent != nil {
+		return hook.parent.notifiers()
+	}
+	hook.mu.RLock()
+	defer hook.mu.RUnlock()
+	return hook.notifier, hook.base
+}
+
+// notify sends err, reporting entry, to Bugsnag, and to the mirror if one is
+// configured. If the hook's configuration is asynchronous, err is delivered in
+// the background. Otherwise, it is delivered in the background only if it
+// failed and is retried, and the delivery is abandoned if ctx or the context
+// of the entry is done first, or if the notify timeout expires.
+func (hook *bugsnagHook) notify(ctx context.Context, entry *logrus.Entry, err *bugsnag_errors.Error, rawData []interface{}) error {
+	// Resolve the stack frames before err may be shared with background
+	// deliveries, as they are computed lazily.
+	err.StackFrames()
+
+	if simple := hook.degraded(); simple != nil {
+		// Deliver synchronously with as little machinery as possible.
+		sendErr := simple.Notify(err, rawData...)
+		if isCanceledByCallback(sendErr) {
+			hook.canceled()
 			return nil
 		}
-		notifyErr = err
-	} else {
-		notifyErr = errors.New(entry.Message)
+		hook.delivered(entry, err, rawData, sendErr)
+		if sendErr != nil {
+			return ErrBugsnagSendFailed{sendErr}
+		}
+		return nil
 	}
 
-	metadata := bugsnag.MetaData{}
-	metadata["metadata"] = make(map[string]interface{})
-	for key, val := range entry.Data {
-		if key != "error" {
-			metadata["metadata"][key] = val
+	if hook.mirror != nil {
+		mirrorData := append([]interface{}(nil), rawData...)
+		hook.deliverInBackground(synchronous{hook.mirror}, err, mirrorData, 0, nil)
+	}
+
+	onDone := func(deliveryErr error) {
+		hook.delivered(entry, err, rawData, deliveryErr)
+	}
+	pending := pendingEvent{err, rawData, entry.Time}
+	var sender, retrySender Notifier
+	var timeout context.Context
+	bound := false
+	if hook.sender != nil {
+		sender, retrySender = hook.sender, hook.sender
+	} else {
+		notifier, _ := hook.notifiers()
+		// Batched events wait for their batch, so they are always delivered
+		// in the background.
+		if !notifier.Config.Synchronous || hook.batch != nil {
+			hook.deliverInBackground(synchronous{notifier}, err, rawData, 0, hook.trackDelivery(pending, onDone))
+			return nil
+		}
+		// ctx, the context of the entry and the notify timeout bound
+		// synchronous deliveries.
+		sender, retrySender = notifier, synchronous{notifier}
+		deliveryCtx, cancel := mergeContexts(ctx, entry.Context)
+		if hook.notifyTimeout > 0 {
+			if deliveryCtx == nil {
+				deliveryCtx, cancel = ctx, func() {}
+			}
+			var cancelTimeout context.CancelFunc
+			timeout, cancelTimeout = context.WithTimeout(deliveryCtx, hook.notifyTimeout)
+			defer cancelTimeout()
+			deliveryCtx = timeout
+		}
+		if deliveryCtx != nil {
+			defer cancel()
+			sender = withContext(notifier, deliveryCtx)
+			bound = true
 		}
 	}
 
-	skipStackFrames := calcSkipStackFrames(bugsnag_errors.New(notifyErr, 0))
-	errWithStack := bugsnag_errors.New(notifyErr, skipStackFrames)
-	bugsnagErr := bugsnag.Notify(errWithStack, metadata)
-	if bugsnagErr != nil {
-		return ErrBugsnagSendFailed{bugsnagErr}
+	sendErr := sender.Notify(err, rawData...)
+	if sendErr != nil && bound {
+		if ctx.Err() != nil {
+			hook.abandoned()
+			return ctx.Err()
+		}
+		if entry.Context != nil && entry.Context.Err() != nil {
+			hook.abandoned()
+			return nil
+		}
+		if timeout != nil && timeout.Err() != nil {
+			hook.abandoned()
+			return ErrBugsnagSendFailed{context.DeadlineExceeded}
+		}
+	}
+	if isCanceledByCallback(sendErr) {
+		hook.canceled()
+		return nil
+	}
+	if sendErr != nil && hook.retry.maxAttempts > 1 {
+		// Retry without blocking the caller.
+		hook.deliverInBackground(retrySender, err, rawData, 1, hook.trackDelivery(pending, onDone))
+		return nil
+	}
+	hook.delivered(entry, err, rawData, sendErr)
+	if sendErr != nil {
+		return ErrBugsnagSendFailed{sendErr}
 	}
 
 	return nil
 }
 
-// If error is type context cancelled, we do not want to log the error in bugsnag
+// If error is type context cancelled, we do not want to log the error in bugsnag.
+// The error may wrap context.Canceled, as url.Error does. Deadlines exceeded
+// are still reported.
 func isContextCanceled(err error) bool {
-	if err == context.Canceled {
-		return true
-	}
-	uerr, ok := err.(*url.Error)
-	return ok && uerr.Err == context.Canceled
+	return errors.Is(err, context.Canceled)
+}
+
+// defaultLevels are the levels reported by a hook: everything at or above the
+// "Error" level.
+var defaultLevels = []logrus.Level{
+	logrus.ErrorLevel,
+	logrus.FatalLevel,
+	logrus.PanicLevel,
 }
 
 // Levels enumerates the log levels on which the error should be forwarded to
-// bugsnag: everything at or above the "Error" level.
+// bugsnag: everything at or above the "Error" level, plus the "Warn" level for
+// hooks created with NewBugsnagHookWithWarnings. Hooks created with
+// WithForceField receive all levels, to report forced entries.
 func (hook *bugsnagHook) Levels() []logrus.Level {
-	return []logrus.Level{
-		logrus.ErrorLevel,
-		logrus.FatalLevel,
-		logrus.PanicLevel,
+	if hook.forceField {
+		return logrus.AllLevels
 	}
+	return hook.levels
 }
 
 const (
@@ -99,18 +632,31 @@ const (
 	logrusBugsnagPkg = "github.com/vend/logrus-bugsnag"
 )
 
-// calcSkipStackFrames calculates the offset to first stackframe that does
-// not belong to log, logrus or logrus-bugsnag.
+// defaultSkipPackages are the packages whose frames are skipped at the top of
+// the stack traces sent by the hook.
+var defaultSkipPackages = []string{logPkg, logrusPkg, logrusBugsnagPkg}
+
+// CalcSkipStackFrames calculates the offset to first stackframe that does
+// not belong to one of skipPackages, which are matched as substrings of the
+// frames' package paths. The returned offset can be passed to
+// bugsnag_errors.New from the function that created err.
 //
 // We do this dynamically because calling log.WithFields().Error(),
 // log.Error() and log.Errorf() generates different stracktrace lengths.
-func calcSkipStackFrames(err *bugsnag_errors.Error) int {
+func CalcSkipStackFrames(err *bugsnag_errors.Error, skipPackages []string) int {
 	for i, stackFrame := range err.StackFrames() {
-		if !strings.Contains(stackFrame.Package, logPkg) &&
-			!strings.Contains(stackFrame.Package, logrusPkg) &&
-			!strings.Contains(stackFrame.Package, logrusBugsnagPkg) {
+		if !containsAny(stackFrame.Package, skipPackages) && i > 0 {
 			return i - 1
 		}
 	}
 	return 0
 }
+
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,71 +1,129 @@
 package logrus_bugsnag
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"net/http"
-	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
 	bugsnag "github.com/bugsnag/bugsnag-go"
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
 )
 
-type stackFrame struct {
-	Method     string `json:"method"`
-	File       string `json:"file"`
-	LineNumber int    `json:"lineNumber"`
+// configureBugsnag points the global bugsnag configuration at the given
+// endpoints.
+func configureBugsnag(endpoints bugsnag.Endpoints) {
+	bugsnag.Configure(bugsnag.Configuration{
+		Endpoints:    endpoints,
+		ReleaseStage: "production",
+		APIKey:       bugsnagtest.APIKey,
+		Synchronous:  true,
+	})
+}
+
+// newTestLogger configures bugsnag to report to a fake Bugsnag server and
+// returns a logger with a hook created with the given options installed. The
+// returned function shuts the fake server down.
+func newTestLogger(t *testing.T, opts ...Option) (*logrus.Logger, *bugsnagHook, *bugsnagtest.Capture, func()) {
+	c, teardown := bugsnagtest.StartCapture(t)
+	configureBugsnag(c.Endpoints())
+	hook, err := NewBugsnagHook(opts...)
+	require.NoError(t, err, "failed to create hook")
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+	return log, hook, c, teardown
+}
+
+// receiveEvent waits for the next event sent to the fake Bugsnag server.
+func receiveEvent(t *testing.T, c *bugsnagtest.Capture) bugsnagtest.Event {
+	t.Helper()
+	event, err := c.WaitForEvent(time.Second)
+	require.NoError(t, err, "no notice received by Bugsnag API")
+	return event
 }
 
-type exception struct {
-	Message    string       `json:"message"`
-	Stacktrace []stackFrame `json:"stacktrace"`
+// fakeNotifier records the errors a hook sends, without network I/O.
+type fakeNotifier struct {
+	mu    sync.Mutex
+	calls []notifyCall
+	// failWith, if set, is returned by Notify.
+	failWith error
 }
 
-type event struct {
-	Exceptions []exception      `json:"exceptions"`
-	Metadata   bugsnag.MetaData `json:"metaData"`
+// notifyCall is an error sent to a fakeNotifier.
+type notifyCall struct {
+	err     error
+	rawData []interface{}
+}
+
+func (n *fakeNotifier) Notify(err error, rawData ...interface{}) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.calls = append(n.calls, notifyCall{err: err, rawData: rawData})
+	return n.failWith
+}
+
+// sent returns the calls received so far, and forgets them.
+func (n *fakeNotifier) sent() []notifyCall {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	calls := n.calls
+	n.calls = nil
+	return calls
+}
+
+// messagesOf returns the messages of the errors of calls.
+func messagesOf(calls []notifyCall) []string {
+	var msgs []string
+	for _, call := range calls {
+		msgs = append(msgs, call.err.Error())
+	}
+	return msgs
+}
+
+// metadata returns the metadata sent with the call.
+func (c notifyCall) metadata() bugsnag.MetaData {
+	for _, datum := range c.rawData {
+		if md, ok := datum.(bugsnag.MetaData); ok {
+			return md
+		}
+	}
+	return nil
 }
 
-type notice struct {
-	Events []event `json:"events"`
+// newFakeLogger returns a logger with a hook created with the given options,
+// sending to a fakeNotifier.
+func newFakeLogger(t *testing.T, opts ...Option) (*logrus.Logger, *bugsnagHook, *fakeNotifier) {
+	notifier := &fakeNotifier{}
+	hook, err := NewBugsnagHook(append([]Option{WithRegistry(nil), WithNotifier(notifier)}, opts...)...)
+	require.NoError(t, err, "failed to create hook")
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+	return log, hook, notifier
 }
 
 func TestNoticeReceived(t *testing.T) {
-	c := make(chan event, 1)
 	expectedMessage := "foo"
 	expectedMetadataLen := 3
 	expectedFields := []string{"animal", "size", "omg"}
 	expectedValues := []interface{}{"walrus", float64(9009), true}
 
-	// create server to retrieve notification into a channel.
-	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var notice notice
-		data, err := ioutil.ReadAll(r.Body)
-		require.NoError(t, err)
-		err = json.Unmarshal(data, &notice)
-		require.NoError(t, err)
-		err = r.Body.Close()
-		require.NoError(t, err)
-		c <- notice.Events[0]
-	}))
-	defer ts.Close()
+	// create server to retrieve notifications.
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
 
-	ts2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-	}))
-	defer ts2.Close()
-
-	bugsnag.Configure(bugsnag.Configuration{
-		Endpoints:    bugsnag.Endpoints{Notify: ts.URL, Sessions: ts2.URL},
-		ReleaseStage: "production",
-		APIKey:       "12345678901234567890123456789012",
-		Synchronous:  true,
-	})
+	configureBugsnag(c.Endpoints())
 
 	// Add hook
 	hook, err := NewBugsnagHook()
@@ -81,44 +139,580 @@ func TestNoticeReceived(t *testing.T) {
 		"omg":    true,
 	}).Error("Bugsnag will not see this string")
 
-	select {
-	case event := <-c:
-		exception := event.Exceptions[0]
-		assert.Equal(t, expectedMessage, exception.Message,
-			fmt.Sprintf("Unexpected message received: got %q, expected %q", exception.Message, expectedMessage))
-
-		assert.True(t, len(exception.Stacktrace) > 1, "Bugsnag error does not have a stack trace")
-		metadata, ok := event.Metadata["metadata"]
-		assert.True(t, ok, "Expected a Metadata field to be present in the bugsnag metadata")
-		assert.Equal(t, expectedMetadataLen, len(metadata))
-
-		for idx, field := range expectedFields {
-			val, ok := metadata[field]
-			assert.True(t, ok, fmt.Sprintf("Expected field %q not found", field))
-			assert.Equal(t, expectedValues[idx], val,
-				fmt.Sprintf("For field %q, found value %v, expected value %v", field, val, expectedValues[idx]))
-		}
+	event := receiveEvent(t, c)
+	exception := event.Exceptions[0]
+	assert.Equal(t, expectedMessage, exception.Message,
+		fmt.Sprintf("Unexpected message received: got %q, expected %q", exception.Message, expectedMessage))
 
-		topFrameMethod := exception.Stacktrace[0].Method
-		assert.Equal(t, "TestNoticeReceived", topFrameMethod,
-			fmt.Sprintf("Unexpected method on top of call stack: got %q, expected TestNoticeReceived", topFrameMethod))
+	assert.True(t, len(exception.Stacktrace) > 1, "Bugsnag error does not have a stack trace")
+	metadata, ok := event.Metadata["metadata"]
+	assert.True(t, ok, "Expected a Metadata field to be present in the bugsnag metadata")
+	assert.Equal(t, expectedMetadataLen, len(metadata))
 
-	case <-time.After(time.Second):
-		t.Fatal("Timed out; no notice received by Bugsnag API")
+	for idx, field := range expectedFields {
+		val, ok := metadata[field]
+		assert.True(t, ok, fmt.Sprintf("Expected field %q not found", field))
+		assert.Equal(t, expectedValues[idx], val,
+			fmt.Sprintf("For field %q, found value %v, expected value %v", field, val, expectedValues[idx]))
 	}
 
+	topFrameMethod := exception.Stacktrace[0].Method
+	assert.Equal(t, "TestNoticeReceived", topFrameMethod,
+		fmt.Sprintf("Unexpected method on top of call stack: got %q, expected TestNoticeReceived", topFrameMethod))
+
 	// will generate a different stacktrace compared to log.WithFields().Error()
 	log.Errorf("Another error")
 
-	select {
-	case event := <-c:
-		topFrame := event.Exceptions[0].Stacktrace[0]
-		if topFrame.Method != "TestNoticeReceived" {
-			t.Errorf("Unexpected method on top of call stack: got %q, expected %q", topFrame.Method,
-				"TestNoticeReceived")
+	topFrame := receiveEvent(t, c).Exceptions[0].Stacktrace[0]
+	if topFrame.Method != "TestNoticeReceived" {
+		t.Errorf("Unexpected method on top of call stack: got %q, expected %q", topFrame.Method,
+			"TestNoticeReceived")
+	}
+}
+
+func TestRefreshConfig(t *testing.T) {
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	c2 := bugsnagtest.NewCapture()
+	defer c2.Close()
+
+	configureBugsnag(c.Endpoints())
+	hook, err := NewBugsnagHook()
+	require.NoError(t, err, "failed to create hook")
+	log := logrus.New()
+	log.Hooks.Add(hook)
+
+	// The hook keeps using its snapshot until it is refreshed.
+	configureBugsnag(c2.Endpoints())
+	log.Error("before refresh")
+	assert.Equal(t, "before refresh", receiveEvent(t, c).Exceptions[0].Message)
+	assert.Empty(t, c2.Events(), "Notice sent to the new endpoint before RefreshConfig")
+
+	require.NoError(t, hook.RefreshConfig())
+	log.Error("after refresh")
+	assert.Equal(t, "after refresh", receiveEvent(t, c2).Exceptions[0].Message)
+	assert.Empty(t, c.Pending(), "Notice sent to the old endpoint after RefreshConfig")
+}
+
+// TestConcurrentConfigure is meant to be run with -race.
+func TestConcurrentConfigure(t *testing.T) {
+	const goroutines, logsPerGoroutine = 8, 25
+
+	log, _, c, teardown := newTestLogger(t)
+	defer teardown()
+
+	done := make(chan struct{})
+	reconfigured := make(chan struct{})
+	go func() {
+		defer close(reconfigured)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				// Point the global configuration elsewhere; the hook must
+				// keep reporting to its snapshot.
+				configureBugsnag(bugsnag.Endpoints{Notify: "http://127.0.0.1:1", Sessions: "http://127.0.0.1:1"})
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < logsPerGoroutine; j++ {
+				log.Error("concurrent error")
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	<-reconfigured
+
+	assert.Equal(t, goroutines*logsPerGoroutine, len(c.Events()))
+}
+
+// TestConcurrentFire is meant to be run with -race.
+func TestConcurrentFire(t *testing.T) {
+	const goroutines, logsPerGoroutine = 8, 25
+
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	notifier := bugsnag.New(bugsnag.Configuration{APIKey: bugsnagtest.APIKey, Endpoints: c.Endpoints()})
+	hook, err := NewBugsnagHookWithNotifier(notifier, WithRegistry(nil), WithRateLimit(1000, 1000))
+	require.NoError(t, err)
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+
+	t.Run("fire", func(t *testing.T) {
+		for i := 0; i < goroutines; i++ {
+			i := i
+			t.Run(fmt.Sprint(i), func(t *testing.T) {
+				t.Parallel()
+				for j := 0; j < logsPerGoroutine; j++ {
+					tags := map[string]string{"step": "logged"}
+					ids := []int{i, j}
+					log.WithFields(logrus.Fields{"tags": tags, "ids": ids}).Error("concurrent error")
+					// The events are delivered in the background, after
+					// the fields are modified.
+					tags["step"] = "modified"
+					ids[0] = -1
+				}
+			})
+		}
+	})
+	require.NoError(t, hook.Flush(context.Background()))
+
+	events := c.Events()
+	assert.Equal(t, goroutines*logsPerGoroutine, len(events))
+	for _, event := range events {
+		assert.Equal(t, map[string]interface{}{"step": "logged"}, event.Metadata["metadata"]["tags"])
+		assert.NotEqual(t, float64(-1), event.Metadata["metadata"]["ids"].([]interface{})[0])
+	}
+	assert.Equal(t, int64(goroutines*logsPerGoroutine), hook.Stats().Sent)
+}
+
+func TestRedactedFields(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithRedactedFields("ssn"))
+	defer teardown()
+
+	log.WithFields(logrus.Fields{
+		"Password":  "hunter2",
+		"api_token": "abc123",
+		"user_SSN":  "078-05-1120",
+		"db":        logrus.Fields{"DB_TOKEN": "s3cr3t", "host": "localhost"},
+		"animal":    "walrus",
+	}).Error("redacted")
+
+	metadata := receiveEvent(t, c).Metadata["metadata"]
+	assert.Equal(t, "[REDACTED]", metadata["api_token"])
+	assert.Equal(t, "[REDACTED]", metadata["user_SSN"])
+	assert.Equal(t, map[string]interface{}{"DB_TOKEN": "[REDACTED]", "host": "localhost"}, metadata["db"])
+	assert.Equal(t, "walrus", metadata["animal"])
+	// Fields matching bugsnag's ParamsFilters too are filtered by bugsnag.
+	assert.Equal(t, "[FILTERED]", metadata["Password"])
+}
+
+func TestMirrorConfig(t *testing.T) {
+	mc := bugsnagtest.NewCapture()
+	defer mc.Close()
+
+	log, _, c, teardown := newTestLogger(t, WithMirrorConfig(bugsnag.Configuration{
+		APIKey:      "abcdefabcdefabcdefabcdefabcdefab",
+		Endpoints:   mc.Endpoints(),
+		Synchronous: true,
+	}))
+	defer teardown()
+
+	log.WithField("animal", "walrus").Error("mirrored")
+
+	primary := receiveEvent(t, c)
+	mirrored := receiveEvent(t, mc)
+	assert.Equal(t, "mirrored", primary.Exceptions[0].Message)
+	assert.Equal(t, primary, mirrored)
+}
+
+func TestNewBugsnagHookWithNotifier(t *testing.T) {
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	c2 := bugsnagtest.NewCapture()
+	defer c2.Close()
+
+	newLogger := func(c *bugsnagtest.Capture) *logrus.Logger {
+		notifier := bugsnag.New(bugsnag.Configuration{
+			APIKey:      bugsnagtest.APIKey,
+			Endpoints:   c.Endpoints(),
+			Synchronous: true,
+		})
+		hook, err := NewBugsnagHookWithNotifier(notifier)
+		require.NoError(t, err, "failed to create hook")
+		log := logrus.New()
+		log.Out = ioutil.Discard
+		log.Hooks.Add(hook)
+		return log
+	}
+	tenant1, tenant2 := newLogger(c), newLogger(c2)
+
+	tenant1.Error("tenant 1")
+	tenant2.Error("tenant 2")
+
+	assert.Equal(t, "tenant 1", receiveEvent(t, c).Exceptions[0].Message)
+	assert.Equal(t, "tenant 2", receiveEvent(t, c2).Exceptions[0].Message)
+	assert.Empty(t, c.Pending())
+	assert.Empty(t, c2.Pending())
+
+	_, err := NewBugsnagHookWithNotifier(&bugsnag.Notifier{Config: &bugsnag.Configuration{}})
+	assert.Equal(t, ErrBugsnagUnconfigured, err)
+}
+
+func TestNewBugsnagHookMust(t *testing.T) {
+	apiKey := bugsnag.Config.APIKey
+	bugsnag.Config.APIKey = ""
+	defer func() {
+		bugsnag.Config.APIKey = apiKey
+	}()
+
+	defer func() {
+		r := recover()
+		require.NotNil(t, r, "NewBugsnagHookMust did not panic")
+		assert.Contains(t, r, "bugsnag must be configured")
+	}()
+	NewBugsnagHookMust(WithRegistry(nil))
+}
+
+func TestNewBugsnagHookMustConfigured(t *testing.T) {
+	_, _, _, teardown := newTestLogger(t)
+	defer teardown()
+
+	assert.NotNil(t, NewBugsnagHookMust(WithRegistry(nil)))
+}
+
+func TestNewBugsnagHookWithWarnings(t *testing.T) {
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+
+	configureBugsnag(c.Endpoints())
+	hook, err := NewBugsnagHookWithWarnings()
+	require.NoError(t, err, "failed to create hook")
+	assert.Contains(t, hook.Levels(), logrus.WarnLevel)
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+
+	log.Warn("careful")
+	event := receiveEvent(t, c)
+	assert.Equal(t, "careful", event.Exceptions[0].Message)
+	assert.Equal(t, "warning", event.Severity)
+
+	defaultHook, err := NewBugsnagHook()
+	require.NoError(t, err, "failed to create hook")
+	assert.NotContains(t, defaultHook.Levels(), logrus.WarnLevel)
+}
+
+func TestCalcSkipStackFrames(t *testing.T) {
+	// Capture a stack in which the sort package stands in for a logging
+	// wrapper between the test and the hook.
+	var err *bugsnag_errors.Error
+	values := []int{2, 1}
+	sort.Slice(values, func(i, j int) bool {
+		if err == nil {
+			err = bugsnag_errors.New(errors.New("wrapped"), 0)
+		}
+		return values[i] < values[j]
+	})
+	require.NotNil(t, err)
+	frames := err.StackFrames()
+
+	// The wrapper's frames end the skipped frames.
+	skip := CalcSkipStackFrames(err, defaultSkipPackages)
+	assert.Equal(t, "sort", frames[skip+1].Package)
+
+	// When skipped, the top frame is the one calling the wrapper.
+	skip = CalcSkipStackFrames(err, append(defaultSkipPackages, "sort"))
+	assert.Equal(t, "TestCalcSkipStackFrames", frames[skip].Name)
+
+	// Nothing is skipped when no package is given.
+	assert.Equal(t, 0, CalcSkipStackFrames(err, nil))
+}
+
+// logRecursively logs an error depth calls deep.
+func logRecursively(log *logrus.Logger, depth int) {
+	if depth > 0 {
+		logRecursively(log, depth-1)
+		return
+	}
+	log.Error("deep")
+}
+
+func TestMaxStackDepth(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithMaxStackDepth(5))
+	defer teardown()
+	// Frames of this package are skipped unless the caller is known.
+	log.SetReportCaller(true)
+
+	logRecursively(log, 30)
+	stack := receiveEvent(t, c).Exceptions[0].Stacktrace
+	require.Len(t, stack, 5)
+	assert.Equal(t, "logRecursively", stack[0].Method)
+
+	// Shorter stack traces are unchanged.
+	log.Error("shallow")
+	assert.Len(t, receiveEvent(t, c).Exceptions[0].Stacktrace, 3)
+
+	log, _, c, teardown = newTestLogger(t)
+	defer teardown()
+	log.SetReportCaller(true)
+	logRecursively(log, 30)
+	assert.True(t, len(receiveEvent(t, c).Exceptions[0].Stacktrace) > 30)
+}
+
+func TestIsContextCanceled(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"canceled", context.Canceled, true},
+		{"wrapped", fmt.Errorf("wrap: %w", context.Canceled), true},
+		{"url error", &url.Error{Op: "Get", URL: "http://example.com", Err: context.Canceled}, true},
+		{"wrapped in url error", &url.Error{Op: "Get", URL: "http://example.com", Err: fmt.Errorf("wrap: %w", context.Canceled)}, true},
+		{"deadline exceeded", context.DeadlineExceeded, false},
+		{"other error", errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isContextCanceled(tt.err))
+		})
+	}
+}
+
+func TestFireIgnoresWrappedCancellation(t *testing.T) {
+	log, hook, notifier := newFakeLogger(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	log.WithError(fmt.Errorf("operation failed: %w", ctx.Err())).Error("call failed")
+	log.WithError(&url.Error{Op: "Get", URL: "http://example.com", Err: fmt.Errorf("dial: %w", ctx.Err())}).Error("call failed")
+	log.WithError(fmt.Errorf("operation failed: %v", ctx.Err())).Error("call failed")
+
+	// Only the error that does not wrap the cancellation is reported.
+	assert.Equal(t, []string{"operation failed: context canceled"}, messagesOf(notifier.sent()))
+	assert.Equal(t, int64(2), hook.Stats().Ignored)
+}
+
+func TestDeferredConfigCheck(t *testing.T) {
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+
+	apiKey := bugsnag.Config.APIKey
+	bugsnag.Config.APIKey = ""
+	defer func() {
+		bugsnag.Config.APIKey = apiKey
+	}()
+
+	_, err := NewBugsnagHook()
+	assert.Equal(t, ErrBugsnagUnconfigured, err)
+
+	hook, err := NewBugsnagHook(WithDeferredConfigCheck(false))
+	require.NoError(t, err, "failed to create hook")
+	reportingHook, err := NewBugsnagHook(WithDeferredConfigCheck(true))
+	require.NoError(t, err, "failed to create hook")
+
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+
+	log.Error("dropped")
+	assert.Equal(t, ErrBugsnagUnconfigured, reportingHook.Fire(logrus.NewEntry(log)))
+
+	configureBugsnag(c.Endpoints())
+	log.Error("delivered")
+	assert.Equal(t, "delivered", receiveEvent(t, c).Exceptions[0].Message)
+	assert.Empty(t, c.Pending())
+}
+
+func TestSetEnabled(t *testing.T) {
+	log, hook, notifier := newFakeLogger(t, WithDisabled())
+
+	assert.NoError(t, hook.Fire(logrus.NewEntry(log).WithField("error", errors.New("disabled"))))
+	log.Error("disabled")
+	assert.Empty(t, notifier.sent())
+
+	hook.SetEnabled(true)
+	log.Error("enabled")
+	assert.Equal(t, []string{"enabled"}, messagesOf(notifier.sent()))
+
+	hook.SetEnabled(false)
+	log.Error("disabled again")
+	assert.Empty(t, notifier.sent())
+	assert.Equal(t, Stats{Attempted: 4, Sent: 1, Ignored: 3}, counters(hook.Stats()))
+}
+
+func TestInitializationSuppression(t *testing.T) {
+	log, _, notifier := newFakeLogger(t, WithInitializationSuppression(100*time.Millisecond))
+
+	log.Error("database not ready")
+	assert.Empty(t, notifier.sent())
+
+	time.Sleep(100 * time.Millisecond)
+	log.Error("after startup")
+	assert.Equal(t, []string{"after startup"}, messagesOf(notifier.sent()))
+}
+
+func TestWithNotifier(t *testing.T) {
+	apiKey := bugsnag.Config.APIKey
+	bugsnag.Config.APIKey = ""
+	defer func() { bugsnag.Config.APIKey = apiKey }()
+
+	// The hook needs no bugsnag configuration.
+	log, hook, notifier := newFakeLogger(t)
+	log.WithField("animal", "walrus").Warn("not reported")
+	log.WithField("animal", "walrus").Error("reported")
+
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "reported", calls[0].err.Error())
+	assert.Equal(t, "walrus", calls[0].metadata()["metadata"]["animal"])
+	assert.Equal(t, Stats{Attempted: 1, Sent: 1}, counters(hook.Stats()))
+}
+
+func TestErrBugsnagSendFailedUnwrap(t *testing.T) {
+	cause := &url.Error{Op: "Post", URL: "https://notify.bugsnag.com", Err: errors.New("connection refused")}
+	notifier := &fakeNotifier{failWith: cause}
+	hook, err := NewBugsnagHook(WithRegistry(nil), WithNotifier(notifier))
+	require.NoError(t, err)
+
+	fireErr := hook.Fire(logrus.NewEntry(logrus.New()).WithField("error", errors.New("failed")))
+	var sendErr ErrBugsnagSendFailed
+	require.True(t, errors.As(fireErr, &sendErr))
+	assert.Equal(t, cause, sendErr.Unwrap())
+	assert.True(t, errors.Is(fireErr, cause))
+
+	var urlErr *url.Error
+	require.True(t, errors.As(fireErr, &urlErr))
+	assert.Equal(t, "Post", urlErr.Op)
+}
+
+func TestSilentFailures(t *testing.T) {
+	cause := errors.New("connection refused")
+	var sendErrors []error
+	onSendError := func(err error, entry *logrus.Entry) {
+		sendErrors = append(sendErrors, err)
+	}
+	notifier := &fakeNotifier{failWith: cause}
+	hook, err := NewBugsnagHook(WithRegistry(nil), WithNotifier(notifier), WithSilentFailures(true), WithOnSendError(onSendError))
+	require.NoError(t, err)
+
+	assert.NoError(t, hook.Fire(logrus.NewEntry(logrus.New()).WithField("error", errors.New("failed"))))
+	assert.Equal(t, []error{cause}, sendErrors)
+	assert.Equal(t, Stats{Attempted: 1, Failed: 1}, counters(hook.Stats()))
+
+	hook, err = NewBugsnagHook(WithRegistry(nil), WithNotifier(notifier))
+	require.NoError(t, err)
+	assert.Error(t, hook.Fire(logrus.NewEntry(logrus.New()).WithField("error", errors.New("failed"))))
+}
+
+func TestWithApprovalFn(t *testing.T) {
+	type tenantKey struct{}
+	var approved []error
+	approve := func(ctx context.Context, notifyErr error, entry *logrus.Entry) bool {
+		if entry.Data["tenant"] == "load-test" || ctx.Value(tenantKey{}) == "load-test" {
+			return false
 		}
+		approved = append(approved, notifyErr)
+		return true
+	}
+	log, hook, notifier := newFakeLogger(t, WithApprovalFn(approve))
+
+	log.WithField("tenant", "load-test").WithError(errors.New("rejected")).Error("failed")
+	log.WithContext(context.WithValue(context.Background(), tenantKey{}, "load-test")).Error("rejected")
+	log.WithField("tenant", "acme").WithError(errors.New("approved")).Error("failed")
+	log.Error("approved")
+
+	assert.Equal(t, []string{"approved", "approved"}, messagesOf(notifier.sent()))
+	assert.Equal(t, "approved", approved[0].Error())
+	assert.Equal(t, Stats{Attempted: 4, Sent: 2, Ignored: 2}, counters(hook.Stats()))
+}
+
+// nopNotifier discards the events sent by the hook, so that benchmarks
+// measure the hook rather than network I/O.
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(err error, rawData ...interface{}) error {
+	return nil
+}
+
+// newBenchmarkHook returns a hook sending to a nopNotifier.
+func newBenchmarkHook(b *testing.B, opts ...Option) *bugsnagHook {
+	hook, err := NewBugsnagHook(append([]Option{WithRegistry(nil), WithNotifier(nopNotifier{})}, opts...)...)
+	require.NoError(b, err, "failed to create hook")
+	return hook
+}
+
+// benchmarkEntry returns an error entry with n fields of mixed types, whose
+// keys start with prefix.
+func benchmarkEntry(n int, prefix string) *logrus.Entry {
+	fields := make(logrus.Fields, n+1)
+	fields[logrus.ErrorKey] = errors.New("connection reset")
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("%s%d", prefix, i)
+		switch i % 3 {
+		case 0:
+			fields[key] = i
+		case 1:
+			fields[key] = key
+		default:
+			fields[key] = map[string]interface{}{"id": i, "name": key}
+		}
+	}
+	entry := logrus.NewEntry(logrus.New()).WithFields(fields)
+	entry.Level = logrus.ErrorLevel
+	entry.Message = "request failed"
+	entry.Time = time.Now()
+	return entry
+}
+
+// fireAtDepth fires entry at hook from depth nested calls.
+func fireAtDepth(hook *bugsnagHook, entry *logrus.Entry, depth int) error {
+	if depth > 0 {
+		return fireAtDepth(hook, entry, depth-1)
+	}
+	return hook.Fire(entry)
+}
+
+func BenchmarkFire(b *testing.B) {
+	for _, n := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("fields=%d", n), func(b *testing.B) {
+			hook := newBenchmarkHook(b)
+			entry := benchmarkEntry(n, "field_")
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := hook.Fire(entry); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFireWithStackFrameCalc(b *testing.B) {
+	for _, depth := range []int{0, 10, 100} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			hook := newBenchmarkHook(b)
+			entry := benchmarkEntry(10, "field_")
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := fireAtDepth(hook, entry, depth); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
 
-	case <-time.After(time.Second):
-		t.Error("Timed out; no notice received by Bugsnag API")
+// BenchmarkFireHighCardinality fires entries whose field names differ from
+// one entry to the next, as when fields are named after IDs, which grows the
+// field counts of Stats up to their bound.
+func BenchmarkFireHighCardinality(b *testing.B) {
+	for _, n := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("fields=%d", n), func(b *testing.B) {
+			hook := newBenchmarkHook(b)
+			entries := make([]*logrus.Entry, 1024)
+			for i := range entries {
+				entries[i] = benchmarkEntry(n, fmt.Sprintf("user_%d_", i))
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := hook.Fire(entries[i%len(entries)]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
 	}
 }
@@ -0,0 +1,54 @@
+// Package bugsnagcgroup adds the resource limits of the container the process
+// runs in to the events sent by a logrus_bugsnag hook.
+package bugsnagcgroup
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	logrus_bugsnag "github.com/vend/logrus-bugsnag"
+)
+
+// cgroupTab is the name of the tab holding the limits.
+const cgroupTab = "cgroup"
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// WithCgroupMetadata adds a "cgroup" tab to events, holding the memory limit
+// and the CPU quota of the process's cgroup (v1). They are read once, when
+// the option is created. It is a no-op on platforms other than Linux, or if
+// the limits cannot be read.
+func WithCgroupMetadata(enabled bool) logrus_bugsnag.Option {
+	var limits map[string]interface{}
+	if enabled && supported {
+		limits = readLimits(cgroupRoot)
+	}
+	return logrus_bugsnag.WithStaticMetadata(cgroupTab, limits)
+}
+
+// readLimits reads the limits of the cgroup hierarchy mounted at root. Limits
+// that cannot be read are left out.
+func readLimits(root string) map[string]interface{} {
+	limits := make(map[string]interface{})
+	if limit, ok := readInt(filepath.Join(root, "memory", "memory.limit_in_bytes")); ok {
+		limits["memory_limit_bytes"] = limit
+	}
+	if quota, ok := readInt(filepath.Join(root, "cpu", "cpu.cfs_quota_us")); ok {
+		limits["cpu_cfs_quota_us"] = quota
+	}
+	return limits
+}
+
+func readInt(path string) (int64, bool) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
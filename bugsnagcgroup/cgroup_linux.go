@@ -0,0 +1,4 @@
+package bugsnagcgroup
+
+// supported is whether cgroups exist on this platform.
+const supported = true
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package bugsnagcgroup
+
+// supported is whether cgroups exist on this platform.
+const supported = false
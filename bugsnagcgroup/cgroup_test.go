@@ -0,0 +1,31 @@
+package bugsnagcgroup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLimits(t *testing.T) {
+	root, err := ioutil.TempDir("", "cgroup")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	assert.Empty(t, readLimits(root))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "memory"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "cpu"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "memory", "memory.limit_in_bytes"), []byte("536870912\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "cpu", "cpu.cfs_quota_us"), []byte("-1\n"), 0644))
+	assert.Equal(t, map[string]interface{}{
+		"memory_limit_bytes": int64(536870912),
+		"cpu_cfs_quota_us":   int64(-1),
+	}, readLimits(root))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "cpu", "cpu.cfs_quota_us"), []byte("max\n"), 0644))
+	assert.Equal(t, map[string]interface{}{"memory_limit_bytes": int64(536870912)}, readLimits(root))
+}
@@ -0,0 +1,61 @@
+// Package bugsnagchi adds the chi route of the request being served to the
+// events sent by a logrus_bugsnag hook.
+package bugsnagchi
+
+import (
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	logrus_bugsnag "github.com/vend/logrus-bugsnag"
+)
+
+// requestTab is the tab of the request, shared with the request ID of the
+// hook.
+const requestTab = "request"
+
+// WithChiContextExtractor adds the route pattern, the method and the URL
+// parameters of the chi route matched by the request to the "request" tab of
+// events. The route is read from the context of the entry, so entries must be
+// logged with WithContext(r.Context()) from a chi handler.
+func WithChiContextExtractor(enabled bool) logrus_bugsnag.Option {
+	if !enabled {
+		return logrus_bugsnag.WithMetadataPlugins()
+	}
+	return logrus_bugsnag.WithMetadataPlugins(routePlugin{})
+}
+
+// routePlugin adds the chi route of the entry's context.
+type routePlugin struct{}
+
+// Name returns "chi".
+func (routePlugin) Name() string {
+	return "chi"
+}
+
+// Enrich adds the route to the "request" tab. Entries without a chi route
+// context are left alone.
+func (routePlugin) Enrich(entry *logrus.Entry, md bugsnag.MetaData) bugsnag.MetaData {
+	if entry.Context == nil {
+		return md
+	}
+	rctx := chi.RouteContext(entry.Context)
+	if rctx == nil {
+		return md
+	}
+	if pattern := rctx.RoutePattern(); pattern != "" {
+		md.Add(requestTab, "route", pattern)
+	}
+	if rctx.RouteMethod != "" {
+		md.Add(requestTab, "method", rctx.RouteMethod)
+	}
+	if len(rctx.URLParams.Keys) > 0 {
+		params := make(map[string]interface{}, len(rctx.URLParams.Keys))
+		for i, key := range rctx.URLParams.Keys {
+			if i < len(rctx.URLParams.Values) {
+				params[key] = rctx.URLParams.Values[i]
+			}
+		}
+		md.Add(requestTab, "params", params)
+	}
+	return md
+}
@@ -0,0 +1,33 @@
+package bugsnagchi
+
+import (
+	"context"
+	"testing"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutePlugin(t *testing.T) {
+	rctx := chi.NewRouteContext()
+	rctx.RouteMethod = "GET"
+	rctx.RoutePatterns = []string{"/users/*", "/{userID}/orders/{orderID}"}
+	rctx.URLParams.Add("userID", "42")
+	rctx.URLParams.Add("orderID", "1337")
+	ctx := context.WithValue(context.Background(), chi.RouteCtxKey, rctx)
+
+	entry := logrus.NewEntry(logrus.New()).WithContext(ctx)
+	md := routePlugin{}.Enrich(entry, bugsnag.MetaData{})
+	assert.Equal(t, map[string]interface{}{
+		"route":  "/users/{userID}/orders/{orderID}",
+		"method": "GET",
+		"params": map[string]interface{}{"userID": "42", "orderID": "1337"},
+	}, md[requestTab])
+
+	// Entries logged outside chi handlers are left alone.
+	entry = logrus.NewEntry(logrus.New()).WithContext(context.Background())
+	assert.Empty(t, routePlugin{}.Enrich(entry, bugsnag.MetaData{}))
+	assert.Empty(t, routePlugin{}.Enrich(logrus.NewEntry(logrus.New()), bugsnag.MetaData{}))
+}
@@ -0,0 +1,102 @@
+// Package bugsnagprom exposes the Stats of a logrus_bugsnag hook as
+// Prometheus metrics. It is a separate package, so that the hook itself does
+// not depend on the Prometheus client.
+package bugsnagprom
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	logrus_bugsnag "github.com/vend/logrus-bugsnag"
+)
+
+const namespace = "logrus_bugsnag"
+
+// StatsSource is implemented by the hooks of logrus_bugsnag.
+type StatsSource interface {
+	Stats() logrus_bugsnag.Stats
+}
+
+// Collector is a prometheus.Collector reporting the Stats of a hook. Every
+// metric has a "hook" label holding the name the collector was created with.
+type Collector struct {
+	hook StatsSource
+
+	attempted   *prometheus.Desc
+	events      *prometheus.Desc
+	retries     *prometheus.Desc
+	quarantine  *prometheus.Desc
+	spill       *prometheus.Desc
+	circuit     *prometheus.Desc
+	avgPayload  *prometheus.Desc
+	maxPayload  *prometheus.Desc
+	tabAvgBytes *prometheus.Desc
+	fieldEvents *prometheus.Desc
+}
+
+// NewCollector returns a collector reporting the Stats of hook, labelled with
+// name, such as the name the hook was created with WithName. Register it with
+// prometheus.MustRegister.
+func NewCollector(hook StatsSource, name string) *Collector {
+	labels := prometheus.Labels{"hook": name}
+	desc := func(metric, help string, variableLabels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", metric), help, variableLabels, labels)
+	}
+	return &Collector{
+		hook: hook,
+
+		attempted:   desc("entries_attempted_total", "Entries fired at the hook."),
+		events:      desc("events_total", "Entries handled by the hook, by outcome.", "outcome"),
+		retries:     desc("retries_total", "Delivery attempts retried after a failure."),
+		quarantine:  desc("quarantine_events_total", "Events quarantined, released and evicted.", "action"),
+		spill:       desc("spill_events_total", "Events spilled to disk, replayed and skipped.", "action"),
+		circuit:     desc("circuit_state", "State of the circuit breaker: 0 closed, 1 open, 2 half-open."),
+		avgPayload:  desc("payload_avg_bytes", "Average estimated size of the metadata of the events."),
+		maxPayload:  desc("payload_max_bytes", "Largest estimated size of the metadata of the events."),
+		tabAvgBytes: desc("tab_avg_bytes", "Average estimated size of the largest metadata tabs.", "tab"),
+		fieldEvents: desc("field_events_total", "Events sent with the most frequent metadata fields.", "key"),
+	}
+}
+
+// Describe sends the descriptors of the metrics of the collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		c.attempted, c.events, c.retries, c.quarantine, c.spill,
+		c.circuit, c.avgPayload, c.maxPayload, c.tabAvgBytes, c.fieldEvents,
+	} {
+		ch <- d
+	}
+}
+
+// Collect sends the metrics of the current Stats of the hook.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.hook.Stats()
+	counter := func(d *prometheus.Desc, val int64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, float64(val), labels...)
+	}
+	gauge := func(d *prometheus.Desc, val float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, val, labels...)
+	}
+
+	counter(c.attempted, s.Attempted)
+	counter(c.events, s.Sent, "sent")
+	counter(c.events, s.Ignored, "ignored")
+	counter(c.events, s.Dropped, "dropped")
+	counter(c.events, s.Failed, "failed")
+	counter(c.events, s.Abandoned, "abandoned")
+	counter(c.retries, s.Retries)
+	counter(c.quarantine, s.Quarantined, "quarantined")
+	counter(c.quarantine, s.Released, "released")
+	counter(c.quarantine, s.Evicted, "evicted")
+	counter(c.spill, s.Spilled, "spilled")
+	counter(c.spill, s.Replayed, "replayed")
+	counter(c.spill, s.SpillSkipped, "skipped")
+	gauge(c.circuit, float64(s.Circuit))
+
+	gauge(c.avgPayload, s.AvgPayloadBytes)
+	gauge(c.maxPayload, float64(s.MaxPayloadBytes))
+	for _, tab := range s.LargestTabs {
+		gauge(c.tabAvgBytes, tab.AvgBytes, tab.Name)
+	}
+	for _, field := range s.FrequentFields {
+		counter(c.fieldEvents, field.Count, field.Key)
+	}
+}
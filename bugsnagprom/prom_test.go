@@ -0,0 +1,63 @@
+package bugsnagprom
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	logrus_bugsnag "github.com/vend/logrus-bugsnag"
+)
+
+// discardNotifier accepts every event.
+type discardNotifier struct{}
+
+func (discardNotifier) Notify(err error, rawData ...interface{}) error {
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	hook, err := logrus_bugsnag.NewBugsnagHook(logrus_bugsnag.WithRegistry(nil), logrus_bugsnag.WithNotifier(discardNotifier{}))
+	require.NoError(t, err)
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+
+	log.WithField("order", "1").Error("failed to process order")
+	log.WithFields(logrus.Fields{"order": "2", "cart": strings.Repeat("x", 100)}).Error("failed to process order")
+	log.WithField(logrus_bugsnag.SkipField, true).Error("skipped")
+
+	c := NewCollector(hook, "orders")
+	expected := `
+# HELP logrus_bugsnag_entries_attempted_total Entries fired at the hook.
+# TYPE logrus_bugsnag_entries_attempted_total counter
+logrus_bugsnag_entries_attempted_total{hook="orders"} 3
+# HELP logrus_bugsnag_events_total Entries handled by the hook, by outcome.
+# TYPE logrus_bugsnag_events_total counter
+logrus_bugsnag_events_total{hook="orders",outcome="abandoned"} 0
+logrus_bugsnag_events_total{hook="orders",outcome="dropped"} 0
+logrus_bugsnag_events_total{hook="orders",outcome="failed"} 0
+logrus_bugsnag_events_total{hook="orders",outcome="ignored"} 1
+logrus_bugsnag_events_total{hook="orders",outcome="sent"} 2
+# HELP logrus_bugsnag_field_events_total Events sent with the most frequent metadata fields.
+# TYPE logrus_bugsnag_field_events_total counter
+logrus_bugsnag_field_events_total{hook="orders",key="cart"} 1
+logrus_bugsnag_field_events_total{hook="orders",key="order"} 2
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"logrus_bugsnag_entries_attempted_total", "logrus_bugsnag_events_total", "logrus_bugsnag_field_events_total"))
+	assert.Equal(t, 1, testutil.CollectAndCount(c, "logrus_bugsnag_tab_avg_bytes"))
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP logrus_bugsnag_circuit_state State of the circuit breaker: 0 closed, 1 open, 2 half-open.
+# TYPE logrus_bugsnag_circuit_state gauge
+logrus_bugsnag_circuit_state{hook="orders"} 0
+`), "logrus_bugsnag_circuit_state"))
+
+	// The metrics pass the checks of the Prometheus registry.
+	problems, err := testutil.CollectAndLint(c)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
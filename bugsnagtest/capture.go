@@ -0,0 +1,189 @@
+// Package bugsnagtest captures the events sent to Bugsnag in tests, with a
+// fake Bugsnag server.
+//
+//	capture, teardown := bugsnagtest.StartCapture(t)
+//	defer teardown()
+//	log.WithError(err).Error("checkout failed")
+//	event, err := capture.WaitForEvent(time.Second)
+package bugsnagtest
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+)
+
+// APIKey is the API key configured by StartCapture if bugsnag has none.
+const APIKey = "12345678901234567890123456789012"
+
+// ErrTimeout is returned by WaitForEvent if no event arrives in time.
+var ErrTimeout = errors.New("bugsnagtest: timed out waiting for an event")
+
+// StackFrame is a frame of the stack trace of an exception.
+type StackFrame struct {
+	Method     string `json:"method"`
+	File       string `json:"file"`
+	LineNumber int    `json:"lineNumber"`
+	InProject  bool   `json:"inProject"`
+}
+
+// Exception is an error reported by an event.
+type Exception struct {
+	ErrorClass string       `json:"errorClass"`
+	Message    string       `json:"message"`
+	Stacktrace []StackFrame `json:"stacktrace"`
+}
+
+// App describes the application that sent an event.
+type App struct {
+	ReleaseStage string `json:"releaseStage"`
+	Version      string `json:"version"`
+}
+
+// SeverityReason is the reason for the severity of an event.
+type SeverityReason struct {
+	Type string `json:"type"`
+}
+
+// Event is an event received by a Capture. JSON numbers in its metadata are
+// decoded as float64.
+type Event struct {
+	Exceptions   []Exception      `json:"exceptions"`
+	Metadata     bugsnag.MetaData `json:"metaData"`
+	Severity     string           `json:"severity"`
+	GroupingHash string           `json:"groupingHash"`
+	Context      string           `json:"context"`
+	Unhandled    bool             `json:"unhandled"`
+	// SeverityReason tells why the event has its severity, such as
+	// "handledError" or "unhandledPanic".
+	SeverityReason SeverityReason `json:"severityReason"`
+	App            App            `json:"app"`
+}
+
+// Capture is a fake Bugsnag server recording the events it receives. It is
+// safe for concurrent use.
+type Capture struct {
+	notify   *httptest.Server
+	sessions *httptest.Server
+
+	mu     sync.Mutex
+	events []Event
+	// next is the index of the next event returned by WaitForEvent.
+	next int
+	// arrived is closed when an event arrives.
+	arrived chan struct{}
+}
+
+// NewCapture starts a fake Bugsnag server, without configuring bugsnag to
+// use it. Close it when done.
+func NewCapture() *Capture {
+	c := &Capture{arrived: make(chan struct{})}
+	c.notify = httptest.NewServer(c)
+	c.sessions = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	return c
+}
+
+// StartCapture starts a fake Bugsnag server and points the global bugsnag
+// configuration at it, setting an API key if there is none. The returned
+// function shuts the server down.
+func StartCapture(t *testing.T) (*Capture, func()) {
+	t.Helper()
+	c := NewCapture()
+	config := bugsnag.Configuration{Endpoints: c.Endpoints()}
+	if bugsnag.Config.APIKey == "" {
+		config.APIKey = APIKey
+	}
+	bugsnag.Configure(config)
+	return c, c.Close
+}
+
+// Endpoints returns the endpoints of the server, to configure bugsnag with.
+func (c *Capture) Endpoints() bugsnag.Endpoints {
+	return bugsnag.Endpoints{Notify: c.notify.URL, Sessions: c.sessions.URL}
+}
+
+// NotifyURL returns the URL of the notify endpoint of the server.
+func (c *Capture) NotifyURL() string {
+	return c.notify.URL
+}
+
+// Close shuts the server down.
+func (c *Capture) Close() {
+	c.notify.Close()
+	c.sessions.Close()
+}
+
+// Events returns the events received so far, in order of arrival.
+func (c *Capture) Events() []Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Event(nil), c.events...)
+}
+
+// Pending returns the events received but not yet returned by WaitForEvent.
+func (c *Capture) Pending() []Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Event(nil), c.events[c.next:]...)
+}
+
+// WaitForEvent returns the next event received that it has not returned yet,
+// waiting up to timeout for it to arrive. It returns ErrTimeout if none does.
+func (c *Capture) WaitForEvent(timeout time.Duration) (Event, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		c.mu.Lock()
+		if c.next < len(c.events) {
+			event := c.events[c.next]
+			c.next++
+			c.mu.Unlock()
+			return event, nil
+		}
+		arrived := c.arrived
+		c.mu.Unlock()
+
+		select {
+		case <-arrived:
+		case <-timer.C:
+			return Event{}, ErrTimeout
+		}
+	}
+}
+
+// ServeHTTP records the events of a notify request, which may be compressed
+// with gzip. It lets tests wrap the Capture in their own handler, for example
+// to simulate failures.
+func (c *Capture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+	var payload struct {
+		Events []Event `json:"events"`
+	}
+	if err := json.NewDecoder(body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, payload.Events...)
+	close(c.arrived)
+	c.arrived = make(chan struct{})
+}
@@ -0,0 +1,57 @@
+package bugsnagtest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartCapture(t *testing.T) {
+	capture, teardown := StartCapture(t)
+	defer teardown()
+
+	require.NoError(t, bugsnag.Notify(errors.New("checkout failed"), bugsnag.MetaData{"order": {"id": 42}}))
+	event, err := capture.WaitForEvent(time.Second)
+	require.NoError(t, err)
+	require.Len(t, event.Exceptions, 1)
+	assert.Equal(t, "checkout failed", event.Exceptions[0].Message)
+	assert.NotEmpty(t, event.Exceptions[0].Stacktrace)
+	assert.Equal(t, float64(42), event.Metadata["order"]["id"])
+	assert.Equal(t, "warning", event.Severity)
+
+	assert.Empty(t, capture.Pending())
+	assert.Len(t, capture.Events(), 1)
+	_, err = capture.WaitForEvent(10 * time.Millisecond)
+	assert.Equal(t, ErrTimeout, err)
+}
+
+func TestCaptureCompressedBatch(t *testing.T) {
+	capture := NewCapture()
+	defer capture.Close()
+
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	_, err := gz.Write([]byte(`{"events": [{"exceptions": [{"message": "first"}]}, {"exceptions": [{"message": "second"}]}]}`))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	req, err := http.NewRequest(http.MethodPost, capture.NotifyURL(), &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Len(t, capture.Pending(), 2)
+	event, err := capture.WaitForEvent(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "first", event.Exceptions[0].Message)
+	assert.Equal(t, "second", capture.Pending()[0].Exceptions[0].Message)
+}
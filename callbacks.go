@@ -0,0 +1,69 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"sync"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+)
+
+// groupingHash is passed to Notify as raw data to set the grouping hash of the
+// event, which bugsnag-go has no raw data type for.
+type groupingHash string
+
+// entryCallback is passed to Notify as raw data to call the callback set with
+// WithBeforeNotify for the event reporting entry.
+type entryCallback struct {
+	fn    func(*bugsnag.Event, *logrus.Entry) error
+	entry *logrus.Entry
+}
+
+// canceledByCallback is returned by Notify when the callback set with
+// WithBeforeNotify cancels an event.
+type canceledByCallback struct {
+	err error
+}
+
+func (e canceledByCallback) Error() string {
+	return "event canceled by callback: " + e.err.Error()
+}
+
+func (e canceledByCallback) Unwrap() error {
+	return e.err
+}
+
+// isCanceledByCallback reports whether err is returned for an event canceled
+// by the callback set with WithBeforeNotify.
+func isCanceledByCallback(err error) bool {
+	var canceled canceledByCallback
+	return errors.As(err, &canceled)
+}
+
+var registerOnce sync.Once
+
+// registerBeforeNotify installs the global bugsnag callback that applies the
+// raw data types defined by this package. It only affects events sent by the
+// hook.
+func registerBeforeNotify() {
+	registerOnce.Do(func() {
+		bugsnag.OnBeforeNotify(beforeNotify)
+	})
+}
+
+func beforeNotify(event *bugsnag.Event, config *bugsnag.Configuration) error {
+	for _, datum := range event.RawData {
+		if hash, ok := datum.(groupingHash); ok {
+			event.GroupingHash = string(hash)
+		}
+	}
+	// The callback runs last, to see the event as the hook sends it.
+	for _, datum := range event.RawData {
+		if callback, ok := datum.(entryCallback); ok {
+			if err := callback.fn(event, callback.entry); err != nil {
+				return canceledByCallback{err}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"testing"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithBeforeNotify(t *testing.T) {
+	var entries []*logrus.Entry
+	beforeNotify := func(event *bugsnag.Event, entry *logrus.Entry) error {
+		entries = append(entries, entry)
+		if entry.Data["path"] == "/healthz" {
+			return errors.New("health check")
+		}
+		event.GroupingHash = "orders"
+		return nil
+	}
+	log, hook, c, teardown := newTestLogger(t, WithBeforeNotify(beforeNotify))
+	defer teardown()
+
+	log.WithField("path", "/healthz").Error("unhealthy")
+	assert.Empty(t, c.Pending())
+	log.WithField("path", "/orders").Error("failed")
+	event := receiveEvent(t, c)
+	assert.Equal(t, "orders", event.GroupingHash)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "unhealthy", entries[0].Message)
+	assert.Equal(t, "/orders", entries[1].Data["path"])
+	assert.Equal(t, Stats{Attempted: 2, Sent: 1, Ignored: 1}, counters(hook.Stats()))
+
+	// Events sent without the hook are not affected.
+	require.NoError(t, bugsnag.Notify(errors.New("panic")))
+	event = receiveEvent(t, c)
+	assert.Empty(t, event.GroupingHash)
+	assert.Len(t, entries, 2)
+}
@@ -0,0 +1,43 @@
+package logrus_bugsnag
+
+import (
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// callersError carries the stack trace bugsnag reports for an error.
+type callersError struct {
+	error
+	callers []uintptr
+}
+
+func (e callersError) Callers() []uintptr {
+	return e.callers
+}
+
+// atCaller returns the error whose stack trace starts at the frame that
+// logged entry, when the logger reports callers: the frames of captured above
+// it are trimmed. If captured does not contain it, as when the entry is fired
+// from another goroutine, a frame for the caller is prepended to trimmed, the
+// stack trace without the frames of the logging packages. trimmed is returned
+// unchanged when entry has no caller.
+func atCaller(captured, trimmed *bugsnag_errors.Error, entry *logrus.Entry) *bugsnag_errors.Error {
+	if entry.Caller == nil {
+		return trimmed
+	}
+	for i, frame := range captured.StackFrames() {
+		if frame.File == entry.Caller.File && frame.LineNumber == entry.Caller.Line {
+			return withCallers(trimmed, captured.Callers()[i:])
+		}
+	}
+	// The PC of a runtime.Frame is that of the call instruction, while
+	// bugsnag expects a return address.
+	return withCallers(trimmed, append([]uintptr{entry.Caller.PC + 1}, trimmed.Callers()...))
+}
+
+// withCallers returns err with the given stack trace.
+func withCallers(err *bugsnag_errors.Error, callers []uintptr) *bugsnag_errors.Error {
+	withStack := bugsnag_errors.New(callersError{err.Err, callers}, 0)
+	withStack.Err = err.Err
+	return withStack
+}
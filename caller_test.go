@@ -0,0 +1,67 @@
+package logrus_bugsnag
+
+import (
+	"runtime"
+	"testing"
+
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func topFrame(t *testing.T, call notifyCall) bugsnag_errors.StackFrame {
+	err, ok := call.err.(*bugsnag_errors.Error)
+	require.True(t, ok, "the notified error has no stack trace")
+	require.NotEmpty(t, err.StackFrames())
+	return err.StackFrames()[0]
+}
+
+// callerFrame returns its own frame, as logrus reports callers.
+func callerFrame() runtime.Frame {
+	pcs := make([]uintptr, 1)
+	runtime.Callers(1, pcs)
+	frame, _ := runtime.CallersFrames(pcs).Next()
+	return frame
+}
+
+func TestReportCaller(t *testing.T) {
+	for _, reportCaller := range []bool{false, true} {
+		log, _, notifier := newFakeLogger(t)
+		log.SetReportCaller(reportCaller)
+		log.Error("plain")
+		log.WithField("error", assert.AnError).Errorf("with %s", "fields")
+
+		calls := notifier.sent()
+		require.Len(t, calls, 2)
+		for _, call := range calls {
+			assert.Equal(t, "TestReportCaller", topFrame(t, call).Name, "ReportCaller: %t", reportCaller)
+			assert.Equal(t, "*errors.errorString", call.err.(*bugsnag_errors.Error).TypeName())
+		}
+	}
+
+	// The caller is found even when skipping packages trims it.
+	log, _, notifier := newFakeLogger(t, WithSkipPackages("testing"))
+	log.SetReportCaller(true)
+	log.Error("skipped")
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "TestReportCaller", topFrame(t, calls[0]).Name)
+}
+
+func TestReportCallerAsync(t *testing.T) {
+	log, hook, notifier := newFakeLogger(t)
+	frame := callerFrame()
+	entry := logrus.NewEntry(log)
+	entry.Level = logrus.ErrorLevel
+	entry.Message = "async"
+	entry.Caller = &frame
+	require.NoError(t, hook.Fire(entry))
+
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	top := topFrame(t, calls[0])
+	assert.Equal(t, "callerFrame", top.Name)
+	assert.Equal(t, frame.File, top.File)
+	assert.Equal(t, frame.Line, top.LineNumber)
+}
@@ -0,0 +1,70 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+)
+
+// errorChain returns the messages of err and of every error it wraps, from the
+// outermost to the innermost. It returns nil if err does not wrap an error.
+func errorChain(err error) []string {
+	if errors.Unwrap(err) == nil {
+		return nil
+	}
+	var chain []string
+	for ; err != nil; err = errors.Unwrap(err) {
+		chain = append(chain, err.Error())
+	}
+	return chain
+}
+
+// errorChainTab holds the messages of the errors of the chain whose types
+// were given to WithErrorChainTypes, by position in the chain.
+const errorChainTab = "error_chain_types"
+
+// addErrorChainTypes adds the messages of the errors of err's chain whose
+// types match the error chain types to the "error_chain_types" tab, keyed by their
+// position in the chain, from 0 for err itself.
+func (hook *bugsnagHook) addErrorChainTypes(metadata bugsnag.MetaData, err error) {
+	for i := 0; err != nil; i, err = i+1, errors.Unwrap(err) {
+		typ := reflect.TypeOf(err)
+		for _, target := range hook.errorChainTypes {
+			if typ.AssignableTo(target) {
+				metadata.Add(errorChainTab, strconv.Itoa(i), err.Error())
+				break
+			}
+		}
+	}
+}
+
+// MetadataProvider is implemented by errors carrying metadata to send with
+// the events reporting them.
+type MetadataProvider interface {
+	BugsnagMetadata() bugsnag.MetaData
+}
+
+// addErrorMetadata adds the metadata provided by err and by every error it
+// wraps. The fields already in the metadata, such as the entry fields, win
+// over those of the errors, and outer errors win over the errors they wrap.
+func (hook *bugsnagHook) addErrorMetadata(metadata bugsnag.MetaData, err error) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		provider, ok := err.(MetadataProvider)
+		if !ok {
+			continue
+		}
+		for tab, fields := range provider.BugsnagMetadata() {
+			for key, val := range fields {
+				if _, exists := metadata[tab][key]; exists || !hook.fieldAllowed(key) {
+					continue
+				}
+				if metadata[tab] == nil {
+					metadata[tab] = make(map[string]interface{}, len(fields))
+				}
+				metadata[tab][key] = hook.redactor.redact(key, val)
+			}
+		}
+	}
+}
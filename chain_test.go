@@ -0,0 +1,117 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorChain(t *testing.T) {
+	root := errors.New("connection refused")
+	middle := fmt.Errorf("query users: %w", root)
+	outer := fmt.Errorf("load profile: %w", middle)
+
+	assert.Equal(t, []string{
+		"load profile: query users: connection refused",
+		"query users: connection refused",
+		"connection refused",
+	}, errorChain(outer))
+	assert.Nil(t, errorChain(root))
+}
+
+func TestWithErrorChain(t *testing.T) {
+	root := errors.New("connection refused")
+	outer := fmt.Errorf("load profile: %w", fmt.Errorf("query users: %w", root))
+
+	log, _, c, teardown := newTestLogger(t, WithErrorChain(true))
+	defer teardown()
+
+	log.WithError(outer).Error("chain")
+	event := receiveEvent(t, c)
+	assert.Equal(t, []interface{}{
+		"load profile: query users: connection refused",
+		"query users: connection refused",
+		"connection refused",
+	}, event.Metadata["metadata"]["error_chain"])
+
+	log.WithError(root).Error("no chain")
+	event = receiveEvent(t, c)
+	assert.NotContains(t, event.Metadata["metadata"], "error_chain")
+}
+
+// orderError is a domain error carrying metadata.
+type orderError struct {
+	sku string
+	err error
+}
+
+func (e orderError) Error() string {
+	return "order " + e.sku + ": " + e.err.Error()
+}
+
+func (e orderError) Unwrap() error {
+	return e.err
+}
+
+func (e orderError) BugsnagMetadata() bugsnag.MetaData {
+	return bugsnag.MetaData{
+		"order":    {"sku": e.sku, "api_token": "secret"},
+		"metadata": {"tenant_id": "from-error", "request_id": "req-1"},
+	}
+}
+
+// tenantError is wrapped by orderError, and provides a field also provided
+// by it.
+type tenantError struct{}
+
+func (tenantError) Error() string {
+	return "tenant suspended"
+}
+
+func (tenantError) BugsnagMetadata() bugsnag.MetaData {
+	return bugsnag.MetaData{"order": {"sku": "inner", "tenant_plan": "free"}}
+}
+
+func TestErrorMetadata(t *testing.T) {
+	log, _, notifier := newFakeLogger(t)
+	err := fmt.Errorf("checkout: %w", orderError{sku: "SKU-1", err: tenantError{}})
+	log.WithField("tenant_id", "from-entry").WithError(err).Error("checkout failed")
+
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	metadata := calls[0].metadata()
+	assert.Equal(t, map[string]interface{}{
+		"sku":         "SKU-1",
+		"api_token":   "[REDACTED]",
+		"tenant_plan": "free",
+	}, metadata["order"])
+	assert.Equal(t, map[string]interface{}{
+		"tenant_id":  "from-entry",
+		"request_id": "req-1",
+	}, metadata["metadata"])
+}
+
+func TestWithErrorChainTypes(t *testing.T) {
+	root := errors.New("connection refused")
+	middle := &url.Error{Op: "Get", URL: "https://api.example.com/users", Err: root}
+	outer := fmt.Errorf("load profile: %w", middle)
+
+	timeout := new(interface{ Timeout() bool })
+	log, _, c, teardown := newTestLogger(t, WithErrorChainTypes(new(*url.Error), timeout, "ignored", nil))
+	defer teardown()
+
+	log.WithError(outer).Error("failed")
+	event := receiveEvent(t, c)
+	assert.Equal(t, map[string]interface{}{"1": `Get "https://api.example.com/users": connection refused`},
+		event.Metadata["error_chain_types"])
+	assert.NotContains(t, event.Metadata["metadata"], "error_chain")
+	assert.NotContains(t, event.Metadata, "error_chain")
+
+	log.WithError(root).Error("failed")
+	assert.NotContains(t, receiveEvent(t, c).Metadata, "error_chain_types")
+}
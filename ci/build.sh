@@ -1,6 +1,6 @@
 #! /bin/bash
 
-# Download dep and retrieve dependencies.
+# Download and verify the dependencies listed in go.mod and go.sum.
 
-curl https://raw.githubusercontent.com/golang/dep/master/install.sh | sh
-dep ensure
+go mod download
+go mod verify
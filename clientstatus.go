@@ -0,0 +1,32 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ResponseError is implemented by the errors of HTTP clients that carry the
+// response received, such as those wrapped by a *url.Error returned by a
+// client checking the status of its responses.
+type ResponseError interface {
+	error
+	Response() *http.Response
+}
+
+// suppressedStatus reports whether err, or an error it wraps, carries a
+// response whose status code is suppressed.
+func (hook *bugsnagHook) suppressedStatus(err error) bool {
+	if len(hook.suppressedStatusCodes) == 0 {
+		return false
+	}
+	var respErr ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+	resp := respErr.Response()
+	if resp == nil {
+		return false
+	}
+	_, suppressed := hook.suppressedStatusCodes[resp.StatusCode]
+	return suppressed
+}
@@ -0,0 +1,51 @@
+package logrus_bugsnag
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// statusError is returned by a client for responses with an unexpected
+// status.
+type statusError struct {
+	resp *http.Response
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.resp.StatusCode)
+}
+
+func (e statusError) Response() *http.Response {
+	return e.resp
+}
+
+func clientError(status int) error {
+	resp := &http.Response{StatusCode: status, Status: http.StatusText(status)}
+	return &url.Error{Op: "Get", URL: "https://api.example.com/orders/1", Err: statusError{resp}}
+}
+
+func TestSuppressedClientStatusCodes(t *testing.T) {
+	log, hook, notifier := newFakeLogger(t, WithSuppressedClientStatusCodes(http.StatusNotFound, http.StatusTooManyRequests))
+
+	log.WithError(clientError(http.StatusNotFound)).Error("fetching order")
+	log.WithError(fmt.Errorf("sync: %w", clientError(http.StatusTooManyRequests))).Error("fetching order")
+	log.WithError(clientError(http.StatusInternalServerError)).Error("fetching order")
+	log.WithError(&url.Error{Op: "Get", URL: "https://api.example.com", Err: statusError{&http.Response{}}}).Error("no status")
+	log.WithError(fmt.Errorf("connection refused")).Error("fetching order")
+
+	assert.Equal(t, []string{
+		`Get "https://api.example.com/orders/1": unexpected status 500`,
+		`Get "https://api.example.com": unexpected status 0`,
+		"connection refused",
+	}, messagesOf(notifier.sent()))
+	assert.Equal(t, int64(2), hook.Stats().Ignored)
+
+	// Nothing is suppressed by default.
+	log, _, notifier = newFakeLogger(t)
+	log.WithError(clientError(http.StatusNotFound)).Error("fetching order")
+	assert.Len(t, notifier.sent(), 1)
+}
@@ -0,0 +1,66 @@
+package logrus_bugsnag
+
+import (
+	"math"
+	"path"
+	"strconv"
+)
+
+// maxSafeInteger is the largest integer a JSON number holds exactly once
+// decoded as a float64.
+const maxSafeInteger = 1 << 53
+
+// integerCoercion renders integer fields as strings, to preserve their exact
+// value.
+type integerCoercion struct {
+	// keyPatterns are the patterns of the keys whose integer values are
+	// always rendered as strings.
+	keyPatterns []string
+}
+
+// coerce returns val as a string if it is an integer beyond maxSafeInteger
+// or key matches one of the patterns. Other values are returned unchanged.
+func (c *integerCoercion) coerce(key string, val interface{}) interface{} {
+	s, unsafe, ok := formatInteger(val)
+	if !ok {
+		return val
+	}
+	if unsafe || c.matches(key) {
+		return s
+	}
+	return val
+}
+
+func (c *integerCoercion) matches(key string) bool {
+	for _, pattern := range c.keyPatterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// formatInteger returns the decimal form of val if it is an integer, and
+// whether its magnitude exceeds maxSafeInteger.
+func formatInteger(val interface{}) (s string, unsafe bool, ok bool) {
+	switch v := val.(type) {
+	case int:
+		return strconv.FormatInt(int64(v), 10), v > maxSafeInteger || v < -maxSafeInteger, true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), false, true
+	case int64:
+		return strconv.FormatInt(v, 10), v > maxSafeInteger || v < -maxSafeInteger, true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), v > maxSafeInteger, true
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), false, true
+	case uint64:
+		return strconv.FormatUint(v, 10), v > maxSafeInteger, true
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return "", false, false
+		}
+		return strconv.FormatFloat(v, 'f', 0, 64), math.Abs(v) > maxSafeInteger, true
+	}
+	return "", false, false
+}
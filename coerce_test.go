@@ -0,0 +1,46 @@
+package logrus_bugsnag
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegerCoercion(t *testing.T) {
+	fields := logrus.Fields{
+		"big":      int64(1<<53 + 1),
+		"negative": -(1<<53 + 1),
+		"unsigned": uint64(18446744073709551615),
+		"float":    float64(1 << 60),
+		"size":     9009,
+		"ratio":    1.5,
+		"order_id": 42,
+		"user_id":  uint32(7),
+		"name_id":  "walrus",
+	}
+
+	log, _, c, teardown := newTestLogger(t, WithIntegerCoercion("*_id"))
+	defer teardown()
+	log.WithFields(fields).Error("coerced")
+	assert.Equal(t, map[string]interface{}{
+		"big":      "9007199254740993",
+		"negative": "-9007199254740993",
+		"unsigned": "18446744073709551615",
+		"float":    "1152921504606846976",
+		"size":     float64(9009),
+		"ratio":    1.5,
+		"order_id": "42",
+		"user_id":  "7",
+		"name_id":  "walrus",
+	}, receiveEvent(t, c).Metadata["metadata"])
+}
+
+func TestIntegerCoercionDisabled(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t)
+	defer teardown()
+	log.WithFields(logrus.Fields{"order_id": 42, "big": int64(1<<53 + 1)}).Error("not coerced")
+	metadata := receiveEvent(t, c).Metadata["metadata"]
+	assert.Equal(t, float64(42), metadata["order_id"])
+	assert.IsType(t, float64(0), metadata["big"])
+}
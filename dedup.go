@@ -0,0 +1,45 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// CacheClient is a cache shared by the instances of a service, such as a
+// Redis client.
+type CacheClient interface {
+	// SetNX sets key for ttl unless it is already set, and reports whether
+	// it was set.
+	SetNX(ctx context.Context, key string, ttl time.Duration) bool
+}
+
+// distributedDedupPrefix prefixes the cache keys of the events sent.
+const distributedDedupPrefix = "logrus-bugsnag:dedup:"
+
+// distributedDedup suppresses the events another instance already sent.
+type distributedDedup struct {
+	client CacheClient
+	ttl    time.Duration
+}
+
+// sentElsewhere reports whether an event identical to err, reporting entry,
+// was sent by any instance sharing the cache less than the TTL ago. Events
+// are identical if they have the same fingerprint or, without fingerprint
+// fields, the same error type, message and top stack frame.
+func (hook *bugsnagHook) sentElsewhere(entry *logrus.Entry, err *bugsnag_errors.Error) bool {
+	key := string(hook.fingerprint(entry))
+	if key == "" {
+		key = dedupKey(err)
+	}
+	hash := sha256.Sum256([]byte(key))
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return !hook.distributedDedup.client.SetNX(ctx, distributedDedupPrefix+hex.EncodeToString(hash[:16]), hook.distributedDedup.ttl)
+}
@@ -0,0 +1,67 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCache is a CacheClient whose keys never expire.
+type fakeCache struct {
+	mu   sync.Mutex
+	keys map[string]time.Duration
+}
+
+func (c *fakeCache) SetNX(ctx context.Context, key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.keys[key]; ok {
+		return false
+	}
+	if c.keys == nil {
+		c.keys = make(map[string]time.Duration)
+	}
+	c.keys[key] = ttl
+	return true
+}
+
+func TestDistributedDedup(t *testing.T) {
+	cache := &fakeCache{}
+	log, hook, notifier := newFakeLogger(t, WithDistributedDedup(cache, time.Minute))
+	other, _, otherNotifier := newFakeLogger(t, WithDistributedDedup(cache, time.Minute))
+
+	// Identical events have the same top stack frame.
+	for _, l := range []*logrus.Logger{log, other, log} {
+		l.Error("failed to process order")
+	}
+	other.Error("another failure")
+	assert.Len(t, notifier.sent(), 1)
+	calls := otherNotifier.sent()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "another failure", calls[0].err.Error())
+	assert.Equal(t, int64(1), hook.Stats().Dropped)
+	require.Len(t, cache.keys, 2)
+	for key, ttl := range cache.keys {
+		assert.Contains(t, key, distributedDedupPrefix)
+		assert.Equal(t, time.Minute, ttl)
+	}
+}
+
+func TestDistributedDedupFingerprint(t *testing.T) {
+	cache := &fakeCache{}
+	log, _, notifier := newFakeLogger(t, WithDistributedDedup(cache, time.Minute), WithFingerprintFields("order"))
+	other, _, otherNotifier := newFakeLogger(t, WithDistributedDedup(cache, time.Minute), WithFingerprintFields("order"))
+
+	log.WithField("order", "1").Error("failed to process order")
+	other.WithField("order", "1").Error("failed to charge order")
+	other.WithField("order", "2").Error("failed to process order")
+	assert.Len(t, notifier.sent(), 1)
+	calls := otherNotifier.sent()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "2", calls[0].metadata()["metadata"]["order"])
+}
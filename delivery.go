@@ -0,0 +1,304 @@
+package logrus_bugsnag
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+)
+
+// deliveryTransport wraps the transport of a notifier to compress the
+// payloads it sends, and to batch the events sent to its notify endpoint.
+type deliveryTransport struct {
+	base      http.RoundTripper
+	notifyURL string
+	compress  bool
+	// batch is shared by the notifiers of a hook, and nil unless batching is
+	// enabled.
+	batch *batcher
+}
+
+// wrapNotifier returns a notifier sending with n's configuration through a
+// deliveryTransport, or n itself if neither compression nor batching is
+// enabled. n is not modified.
+func (hook *bugsnagHook) wrapNotifier(n *bugsnag.Notifier) *bugsnag.Notifier {
+	if !hook.compress && hook.batch == nil {
+		return n
+	}
+	config := *n.Config
+	base := config.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	config.Transport = &deliveryTransport{
+		base:      base,
+		notifyURL: config.Endpoints.Notify,
+		compress:  hook.compress,
+		batch:     hook.batch,
+	}
+	return &bugsnag.Notifier{Config: &config, RawData: n.RawData}
+}
+
+func (t *deliveryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.batch != nil && req.Method == http.MethodPost && req.URL.String() == t.notifyURL {
+		return t.batch.add(t, req)
+	}
+	return t.send(req)
+}
+
+// send sends req with the base transport, compressing its body if enabled.
+func (t *deliveryTransport) send(req *http.Request) (*http.Response, error) {
+	if !t.compress || req.Body == nil {
+		return t.base.RoundTrip(req)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	compressed := req.Clone(req.Context())
+	compressed.Header.Set("Content-Encoding", "gzip")
+	compressed.Body = ioutil.NopCloser(&buf)
+	compressed.ContentLength = int64(buf.Len())
+	compressed.GetBody = nil
+	return t.base.RoundTrip(compressed)
+}
+
+// contextTransport sends requests with ctx, so that its cancellation or
+// deadline bounds them.
+type contextTransport struct {
+	base http.RoundTripper
+	ctx  context.Context
+}
+
+func (t contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
+
+// withContext returns a notifier sending with n's configuration whose
+// requests are bound by ctx.
+func withContext(n *bugsnag.Notifier, ctx context.Context) *bugsnag.Notifier {
+	config := *n.Config
+	base := config.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	config.Transport = contextTransport{base: base, ctx: ctx}
+	return &bugsnag.Notifier{Config: &config, RawData: n.RawData}
+}
+
+// maxBatchBytes bounds the size of the payload of a batch, as Bugsnag rejects
+// payloads over 1MB.
+const maxBatchBytes = 1000000
+
+// batcher merges the events of notify payloads into a single payload, sent
+// when it holds size events or interval after its first event was added, or
+// before it would exceed maxBytes. Each request adding events to a batch
+// waits for the batch to be sent, and gets the response to the batch.
+type batcher struct {
+	size     int
+	interval time.Duration
+	maxBytes int
+
+	mu      sync.Mutex
+	pending *batch
+	// draining counts the Flush calls in progress, during which events are
+	// sent as soon as they are added.
+	draining int
+}
+
+// batch is a payload waiting to be sent.
+type batch struct {
+	transport *deliveryTransport
+	req       *http.Request
+	payload   map[string]interface{}
+	events    []interface{}
+	bytes     int
+	timer     *time.Timer
+
+	// done is closed once the batch is sent, with the response in resp and
+	// body, or the error sending it in err.
+	done chan struct{}
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+// add adds the events of the payload sent by req to the pending batch, sends
+// the batch if it is full, and returns the response to the batch once it is
+// sent.
+func (b *batcher) add(t *deliveryTransport, req *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	events, _ := payload["events"].([]interface{})
+
+	b.mu.Lock()
+	var previous, full *batch
+	if b.pending != nil && (b.pending.transport != t || b.pending.bytes+len(body) > b.maxBytes) {
+		// The hook's configuration was refreshed, or the events would not
+		// fit: send the events batched so far separately.
+		previous = b.take()
+	}
+	if b.pending == nil {
+		b.pending = &batch{transport: t, req: req, payload: payload, done: make(chan struct{})}
+		b.pending.timer = time.AfterFunc(b.interval, b.flush)
+	}
+	current := b.pending
+	current.events = append(current.events, events...)
+	current.bytes += len(body)
+	if len(current.events) >= b.size || b.draining > 0 {
+		full = b.take()
+	}
+	b.mu.Unlock()
+
+	if previous != nil {
+		previous.send()
+	}
+	if full != nil {
+		full.send()
+	}
+	return current.response(req)
+}
+
+// flush sends the pending batch, if any.
+func (b *batcher) flush() {
+	b.mu.Lock()
+	if b.pending == nil {
+		b.mu.Unlock()
+		return
+	}
+	pending := b.take()
+	b.mu.Unlock()
+	pending.send()
+}
+
+// flushSync sends the pending batch, if any, and returns the error sending
+// it.
+func (b *batcher) flushSync(ctx context.Context) error {
+	b.mu.Lock()
+	if b.pending == nil {
+		b.mu.Unlock()
+		return nil
+	}
+	pending := b.take()
+	b.mu.Unlock()
+
+	pending.req = pending.req.WithContext(ctx)
+	return pending.send()
+}
+
+// drain sends the events added to a batch at once until the returned function
+// is called, so that Flush does not wait for the interval of a batch.
+func (b *batcher) drain() func() {
+	b.mu.Lock()
+	b.draining++
+	b.mu.Unlock()
+	return func() {
+		b.mu.Lock()
+		b.draining--
+		b.mu.Unlock()
+	}
+}
+
+// take removes the pending batch. b.mu must be held.
+func (b *batcher) take() *batch {
+	pending := b.pending
+	b.pending = nil
+	pending.timer.Stop()
+	return pending
+}
+
+// send sends the batch, and returns the error sending it. The response is
+// kept for the requests waiting for the batch.
+func (p *batch) send() error {
+	defer close(p.done)
+	p.payload["events"] = p.events
+	body, err := json.Marshal(p.payload)
+	if err != nil {
+		p.err = err
+		return err
+	}
+	req := p.req.Clone(p.req.Context())
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = nil
+	resp, err := p.transport.send(req)
+	if err != nil {
+		p.err = err
+		return err
+	}
+	defer resp.Body.Close()
+	p.resp = resp
+	p.body, p.err = ioutil.ReadAll(resp.Body)
+	return p.err
+}
+
+// response waits for the batch to be sent, and returns the response to req,
+// one of the requests whose events it holds.
+func (p *batch) response(req *http.Request) (*http.Response, error) {
+	select {
+	case <-p.done:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &http.Response{
+		Status:        p.resp.Status,
+		StatusCode:    p.resp.StatusCode,
+		Proto:         p.resp.Proto,
+		ProtoMajor:    p.resp.ProtoMajor,
+		ProtoMinor:    p.resp.ProtoMinor,
+		Header:        p.resp.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(p.body)),
+		ContentLength: int64(len(p.body)),
+		Request:       req,
+	}, nil
+}
+
+// mergeContexts returns a context done when a or b is done, and the function
+// releasing it. b may be nil. It returns a nil context if neither can be
+// done.
+func mergeContexts(a, b context.Context) (context.Context, context.CancelFunc) {
+	if b == nil || b.Done() == nil {
+		if a.Done() == nil {
+			return nil, nil
+		}
+		return a, func() {}
+	}
+	if a.Done() == nil {
+		return b, func() {}
+	}
+	ctx, cancel := context.WithCancel(a)
+	go func() {
+		select {
+		case <-b.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
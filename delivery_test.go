@@ -0,0 +1,271 @@
+package logrus_bugsnag
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
+)
+
+// recordedRequest is a request received by a recording server.
+type recordedRequest struct {
+	contentEncoding string
+	events          []bugsnagtest.Event
+}
+
+// newRecordingServer returns a fake notify server recording the requests it
+// receives.
+func newRecordingServer(t *testing.T, size int) (*httptest.Server, chan recordedRequest) {
+	c := make(chan recordedRequest, size)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = zr
+		}
+		var payload struct {
+			Events []bugsnagtest.Event
+		}
+		require.NoError(t, json.NewDecoder(body).Decode(&payload))
+		c <- recordedRequest{contentEncoding: r.Header.Get("Content-Encoding"), events: payload.Events}
+	}))
+	return ts, c
+}
+
+func newRecordingLogger(t *testing.T, url string, opts ...Option) (*logrus.Logger, *bugsnagHook) {
+	notifier := bugsnag.New(bugsnag.Configuration{
+		APIKey:      "12345678901234567890123456789012",
+		Endpoints:   bugsnag.Endpoints{Notify: url, Sessions: url},
+		Synchronous: true,
+	})
+	hook, err := NewBugsnagHookWithNotifier(notifier, append([]Option{WithRegistry(nil)}, opts...)...)
+	require.NoError(t, err)
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+	return log, hook
+}
+
+func receiveRequest(t *testing.T, c chan recordedRequest) recordedRequest {
+	select {
+	case req := <-c:
+		return req
+	case <-time.After(time.Second):
+		t.Fatal("Timed out; no request received by the server")
+	}
+	return recordedRequest{}
+}
+
+func messages(events []bugsnagtest.Event) []string {
+	var msgs []string
+	for _, e := range events {
+		msgs = append(msgs, e.Exceptions[0].Message)
+	}
+	return msgs
+}
+
+func TestCompression(t *testing.T) {
+	ts, c := newRecordingServer(t, 2)
+	defer ts.Close()
+
+	log, _ := newRecordingLogger(t, ts.URL)
+	log.Error("plain")
+	req := receiveRequest(t, c)
+	assert.Equal(t, "", req.contentEncoding)
+	assert.Equal(t, []string{"plain"}, messages(req.events))
+
+	log, _ = newRecordingLogger(t, ts.URL, WithCompression(true))
+	log.Error("compressed")
+	req = receiveRequest(t, c)
+	assert.Equal(t, "gzip", req.contentEncoding)
+	assert.Equal(t, []string{"compressed"}, messages(req.events))
+}
+
+func TestBatching(t *testing.T) {
+	ts, c := newRecordingServer(t, 3)
+	defer ts.Close()
+
+	log, hook := newRecordingLogger(t, ts.URL, WithBatching(3, time.Hour), WithCompression(true))
+	for _, msg := range []string{"1", "2", "3", "4", "5", "6", "7"} {
+		log.Error(msg)
+	}
+	// The events are added to the batches in the background, in any order.
+	req := receiveRequest(t, c)
+	assert.Equal(t, "gzip", req.contentEncoding)
+	sent := messages(req.events)
+	assert.Len(t, sent, 3)
+	req = receiveRequest(t, c)
+	assert.Len(t, req.events, 3)
+	sent = append(sent, messages(req.events)...)
+	assert.Empty(t, c)
+
+	require.NoError(t, hook.Flush(context.Background()))
+	sent = append(sent, messages(receiveRequest(t, c).events)...)
+	assert.ElementsMatch(t, []string{"1", "2", "3", "4", "5", "6", "7"}, sent)
+	assert.Equal(t, Stats{Attempted: 7, Sent: 7}, counters(hook.Stats()))
+	require.NoError(t, hook.Flush(context.Background()))
+	assert.Empty(t, c)
+}
+
+func TestBatchingInterval(t *testing.T) {
+	ts, c := newRecordingServer(t, 1)
+	defer ts.Close()
+
+	log, _ := newRecordingLogger(t, ts.URL, WithBatching(10, 50*time.Millisecond))
+	log.Error("1")
+	log.Error("2")
+	req := receiveRequest(t, c)
+	assert.Equal(t, "", req.contentEncoding)
+	assert.ElementsMatch(t, []string{"1", "2"}, messages(req.events))
+}
+
+func TestBatchingFailure(t *testing.T) {
+	var requests int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	var failed int32
+	onSendError := func(error, *logrus.Entry) {
+		atomic.AddInt32(&failed, 1)
+	}
+	log, hook := newRecordingLogger(t, failing.URL, WithBatching(2, time.Hour), WithOnSendError(onSendError), WithRetry(2, time.Millisecond))
+	log.Error("1")
+	log.Error("2")
+	require.NoError(t, hook.Flush(context.Background()))
+
+	// Each event of the batch fails, and is retried in a batch of its own or
+	// with the other event.
+	assert.Equal(t, int32(2), atomic.LoadInt32(&failed))
+	assert.Equal(t, Stats{Attempted: 2, Failed: 2, Retries: 2}, counters(hook.Stats()))
+	assert.True(t, atomic.LoadInt32(&requests) >= 2, "requests: %d", atomic.LoadInt32(&requests))
+}
+
+func TestBatchingMaxBytes(t *testing.T) {
+	ts, c := newRecordingServer(t, 3)
+	defer ts.Close()
+
+	log, hook := newRecordingLogger(t, ts.URL, WithBatching(3, time.Hour))
+	// Room for a single event of 10KB.
+	hook.batch.maxBytes = 15000
+	for _, msg := range []string{"1", "2", "3"} {
+		log.WithField("body", strings.Repeat("x", 10000)).Error(msg)
+	}
+	require.NoError(t, hook.Flush(context.Background()))
+
+	var sent []string
+	for i := 0; i < 3; i++ {
+		req := receiveRequest(t, c)
+		assert.Len(t, req.events, 1)
+		sent = append(sent, messages(req.events)...)
+	}
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, sent)
+	assert.Equal(t, Stats{Attempted: 3, Sent: 3}, counters(hook.Stats()))
+}
+
+func TestEntryContextBoundsDelivery(t *testing.T) {
+	unblock := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-unblock:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	defer close(unblock)
+
+	log, hook := newRecordingLogger(t, slow.URL, WithRetry(3, time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	assert.NoError(t, hook.Fire(logrus.NewEntry(log).WithContext(ctx).WithField("error", assert.AnError)))
+	assert.True(t, time.Since(start) < time.Second, "the delivery was not canceled")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	log.WithContext(ctx).Error("deadline")
+
+	require.NoError(t, hook.Flush(context.Background()))
+	assert.Equal(t, Stats{Attempted: 2, Abandoned: 2}, counters(hook.Stats()))
+}
+
+func TestFireWithContext(t *testing.T) {
+	unblock := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-unblock:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	defer close(unblock)
+
+	log, hook := newRecordingLogger(t, slow.URL, WithRetry(3, time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	err := hook.FireWithContext(ctx, logrus.NewEntry(log).WithField("error", assert.AnError))
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, time.Since(start) < time.Second, "the delivery was not canceled")
+
+	// The context of the entry still bounds the delivery.
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	entryCtx, cancelEntry := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancelEntry)
+	start = time.Now()
+	assert.NoError(t, hook.FireWithContext(ctx, logrus.NewEntry(log).WithContext(entryCtx).WithField("error", assert.AnError)))
+	assert.True(t, time.Since(start) < time.Second, "the delivery was not canceled")
+
+	require.NoError(t, hook.Flush(context.Background()))
+	assert.Equal(t, Stats{Attempted: 2, Abandoned: 2}, counters(hook.Stats()))
+}
+
+func TestNotifyTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-unblock:
+		case <-r.Context().Done():
+		}
+	}))
+	defer hung.Close()
+	defer close(unblock)
+
+	log, hook := newRecordingLogger(t, hung.URL, WithNotifyTimeout(50*time.Millisecond), WithRetry(3, time.Millisecond))
+	start := time.Now()
+	err := hook.Fire(logrus.NewEntry(log).WithField("error", assert.AnError))
+	assert.True(t, time.Since(start) < time.Second, "the delivery was not abandoned")
+	assert.IsType(t, ErrBugsnagSendFailed{}, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	require.NoError(t, hook.Flush(context.Background()))
+	assert.Equal(t, Stats{Attempted: 1, Abandoned: 1}, counters(hook.Stats()))
+
+	// Deliveries slower than usual but within the timeout succeed.
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	log, hook = newRecordingLogger(t, slow.URL, WithNotifyTimeout(time.Second))
+	assert.NoError(t, hook.Fire(logrus.NewEntry(log).WithField("error", assert.AnError)))
+	assert.Equal(t, Stats{Attempted: 1, Sent: 1}, counters(hook.Stats()))
+}
@@ -0,0 +1,155 @@
+package logrus_bugsnag
+
+import "time"
+
+// NewDerivedHook returns a hook configured like parent, then with opts, such
+// as metadata plugins adding fields to all the events of a worker. The
+// derived hook starts from the settings parent resolved when it was created,
+// so the options of parent are not applied again. It sends its events with
+// the notifier of parent, including after parent.RefreshConfig, and shares
+// its rate limit, deduplication, circuit breaker, batches and mirror, so that
+// both hooks count as one towards Bugsnag. It is not added to the logger
+// parent was added to with WithLogger.
+func NewDerivedHook(parent *bugsnagHook, opts ...Option) *bugsnagHook {
+	hook := parent.derive()
+	for _, opt := range opts {
+		opt(hook)
+	}
+	// Set once the options are applied, for them not to change parent.
+	hook.limiter = parent.limiter
+	hook.breaker = parent.breaker
+	hook.compress = parent.compress
+	hook.batch = parent.batch
+	if hook.name == "" {
+		hook.name = defaultHookName()
+	}
+	hook.install()
+	return hook
+}
+
+// derive returns a hook with the settings of hook, sending its events with
+// the notifier of hook, but without its rate limit, deduplication, circuit
+// breaker and delivery settings. The slices and maps that options add to are
+// copied, for the options of the derived hook not to change hook.
+func (hook *bugsnagHook) derive() *bugsnagHook {
+	hook.mu.RLock()
+	runbookResolver, disabled := hook.runbookResolver, hook.disabled
+	hook.mu.RUnlock()
+
+	derived := &bugsnagHook{
+		parent:          hook,
+		runbookResolver: runbookResolver,
+		disabled:        disabled,
+		registry:        hook.registry,
+
+		runbookURL:  hook.runbookURL,
+		ownNotifier: hook.ownNotifier,
+		sender:      hook.sender,
+
+		deferConfigCheck:   hook.deferConfigCheck,
+		reportUnconfigured: hook.reportUnconfigured,
+
+		levels:     hook.levels,
+		forceField: hook.forceField,
+
+		created:         time.Now(),
+		initSuppression: hook.initSuppression,
+
+		skipPackages:  clip(hook.skipPackages),
+		maxStackDepth: hook.maxStackDepth,
+
+		minimalMatcher:  hook.minimalMatcher,
+		minimalMetadata: hook.minimalMetadata,
+
+		redactor: redactor{fields: clip(hook.redactor.fields), fn: hook.redactor.fn},
+		mirror:   hook.mirror,
+
+		errorChain:          hook.errorChain,
+		promoteTabs:         hook.promoteTabs,
+		normalizeUUIDs:      hook.normalizeUUIDs,
+		normalizeIPs:        hook.normalizeIPs,
+		coercion:            hook.coercion,
+		titleCase:           hook.titleCase,
+		textMarshalerErrors: hook.textMarshalerErrors,
+		typedFields:         hook.typedFields,
+		dropNilFields:       hook.dropNilFields,
+		errorChainTypes:     clip(hook.errorChainTypes),
+		shortErrorClass:     hook.shortErrorClass,
+		errorClassPrefix:    hook.errorClassPrefix,
+		logMessageField:     hook.logMessageField,
+		timestampField:      hook.timestampField,
+		allowedFields:       copySet(hook.allowedFields),
+		fingerprintFields:   hook.fingerprintFields,
+		featureFlagPrefix:   hook.featureFlagPrefix,
+		requestIDHeader:     hook.requestIDHeader,
+		releaseStageField:   hook.releaseStageField,
+		appVersionField:     hook.appVersionField,
+		notifyReleaseStages: hook.notifyReleaseStages,
+		panicValueField:     hook.panicValueField,
+		notifyTimeout:       hook.notifyTimeout,
+
+		ignoredErrors:         clip(hook.ignoredErrors),
+		ignoredErrorTypes:     clip(hook.ignoredErrorTypes),
+		ignoreFuncs:           clip(hook.ignoreFuncs),
+		ignorableMatchers:     clip(hook.ignorableMatchers),
+		unhandledLevels:       hook.unhandledLevels,
+		suppressedStatusCodes: copySet(hook.suppressedStatusCodes),
+		multiErrorMode:        hook.multiErrorMode,
+		maxMultiErrors:        hook.maxMultiErrors,
+
+		profiles: hook.profiles,
+		profile:  hook.profile,
+
+		approve:         hook.approve,
+		sampler:         hook.sampler,
+		suppressFeature: hook.suppressFeature,
+		beforeNotify:    hook.beforeNotify,
+
+		breadcrumbs: hook.breadcrumbs,
+		plugins:     clip(hook.plugins),
+
+		distributedDedup: hook.distributedDedup,
+
+		retry:          hook.retry,
+		onSendError:    hook.onSendError,
+		silentFailures: hook.silentFailures,
+		errorChannel:   hook.errorChannel,
+
+		tagPrefix:             hook.tagPrefix,
+		limits:                hook.limits,
+		tabNameSanitizer:      hook.tabNameSanitizer,
+		messageErrorExtractor: hook.messageErrorExtractor,
+		combineMessages:       hook.combineMessages,
+		unwrapSQLNulls:        hook.unwrapSQLNulls,
+		dryRun:                hook.dryRun,
+	}
+	for tab, fields := range hook.staticTabs {
+		derived.setStaticTab(tab, fields)
+	}
+	// The undelivered events are kept by each hook.
+	if hook.quarantine != nil {
+		derived.quarantine = &quarantine{capacity: hook.quarantine.capacity}
+	}
+	if hook.spill != nil {
+		derived.spill = newSpill(hook.spill.dir, hook.spill.maxAge)
+	}
+	return derived
+}
+
+// clip returns s with no spare capacity, so that appending to it does not
+// write to the array of s.
+func clip[T any](s []T) []T {
+	return s[:len(s):len(s)]
+}
+
+// copySet returns a copy of the set m, or nil if m is nil.
+func copySet[K comparable](m map[K]struct{}) map[K]struct{} {
+	if m == nil {
+		return nil
+	}
+	c := make(map[K]struct{}, len(m))
+	for key := range m {
+		c[key] = struct{}{}
+	}
+	return c
+}
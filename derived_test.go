@@ -0,0 +1,176 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
+	"golang.org/x/time/rate"
+)
+
+// workerPlugin adds the ID of a background worker to events.
+type workerPlugin struct {
+	id int
+}
+
+func (workerPlugin) Name() string {
+	return "worker"
+}
+
+func (p workerPlugin) Enrich(entry *logrus.Entry, md bugsnag.MetaData) bugsnag.MetaData {
+	md.Add("metadata", "worker_id", p.id)
+	return md
+}
+
+func TestNewDerivedHook(t *testing.T) {
+	log, parent, notifier := newFakeLogger(t, WithRedactedFields("ssn"), WithRateLimit(rate.Every(time.Hour), 2))
+	hook := NewDerivedHook(parent, WithMetadataPlugins(workerPlugin{id: 7}))
+	assert.NotEqual(t, parent.name, hook.name)
+	assert.True(t, parent.limiter == hook.limiter, "the limiter is not shared")
+	worker := logrus.New()
+	worker.Out = ioutil.Discard
+	worker.Hooks.Add(hook)
+
+	log.WithField("ssn", "123-45-6789").Error("parent")
+	worker.WithField("ssn", "123-45-6789").Error("worker")
+	// The hooks share the rate limit.
+	worker.Error("rate limited")
+	log.Error("rate limited")
+
+	calls := notifier.sent()
+	require.Len(t, calls, 2)
+	assert.Equal(t, "parent", calls[0].err.Error())
+	assert.Equal(t, map[string]interface{}{"ssn": "[REDACTED]"}, calls[0].metadata()["metadata"])
+	assert.Equal(t, "worker", calls[1].err.Error())
+	assert.Equal(t, map[string]interface{}{"ssn": "[REDACTED]", "worker_id": 7}, calls[1].metadata()["metadata"])
+	assert.Equal(t, int64(1), parent.Stats().Dropped)
+	assert.Equal(t, int64(1), hook.Stats().Dropped)
+}
+
+func TestNewDerivedHookFollowsParent(t *testing.T) {
+	defer func(fn func() (string, error)) { gitRemoteURL = fn }(gitRemoteURL)
+	var detected int
+	gitRemoteURL = func() (string, error) {
+		detected++
+		return "git@github.com:vend/logrus-bugsnag.git", nil
+	}
+
+	_, parent, _, teardown := newTestLogger(t, WithRegistry(nil), WithAutoDetectProject(true), WithMirrorConfig(bugsnag.Configuration{}))
+	defer teardown()
+	hook := NewDerivedHook(parent, WithRedactedFields("ssn"))
+	// The options of the parent are not applied again.
+	assert.Equal(t, 1, detected)
+	assert.True(t, parent.mirror == hook.mirror, "the mirror is not shared")
+	assert.NotContains(t, parent.redactor.fields, "ssn")
+
+	// The derived hook sends its events with the refreshed notifier of its
+	// parent.
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	configureBugsnag(c.Endpoints())
+	require.NoError(t, parent.RefreshConfig())
+	worker := logrus.New()
+	worker.Out = ioutil.Discard
+	worker.Hooks.Add(hook)
+	worker.WithField("ssn", "123-45-6789").Error("worker")
+	event := receiveEvent(t, c)
+	assert.Equal(t, "worker", event.Exceptions[0].Message)
+	assert.Equal(t, "[REDACTED]", event.Metadata["metadata"]["ssn"])
+	assert.Equal(t, map[string]interface{}{"repository": "vend/logrus-bugsnag"}, event.Metadata[sourceTab])
+}
+
+// TestNewDerivedHookCopiesEveryField fails when a field added to bugsnagHook
+// is neither copied by derive or NewDerivedHook nor listed below as state of
+// each hook.
+func TestNewDerivedHookCopiesEveryField(t *testing.T) {
+	perHook := map[string]bool{
+		"mu": true, "notifier": true, "base": true, "closed": true, "failure": true,
+		"name": true, "pending": true, "logger": true, "parent": true, "created": true,
+		"stats": true,
+	}
+	parent := &bugsnagHook{}
+	v := reflect.ValueOf(parent).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if !perHook[v.Type().Field(i).Name] {
+			fill(t, settable(v.Field(i)))
+		}
+	}
+
+	hook := NewDerivedHook(parent)
+	derived := reflect.ValueOf(hook).Elem()
+	for i := 0; i < derived.NumField(); i++ {
+		name := derived.Type().Field(i).Name
+		if perHook[name] {
+			delete(perHook, name)
+			continue
+		}
+		assert.False(t, derived.Field(i).IsZero(), "field %s is not copied by derive", name)
+	}
+	assert.Empty(t, perHook, "unknown fields")
+}
+
+// settable returns v, which may be an unexported field, as a settable value.
+func settable(v reflect.Value) reflect.Value {
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// fill sets v to a value that is not zero.
+func fill(t *testing.T, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1)
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+	case reflect.Slice:
+		elem := reflect.New(v.Type().Elem()).Elem()
+		fill(t, elem)
+		v.Set(reflect.Append(reflect.MakeSlice(v.Type(), 0, 1), elem))
+	case reflect.Map:
+		key, elem := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+		fill(t, key)
+		fill(t, elem)
+		m := reflect.MakeMap(v.Type())
+		m.SetMapIndex(key, elem)
+		v.Set(m)
+	case reflect.Func:
+		v.Set(reflect.MakeFunc(v.Type(), func(args []reflect.Value) []reflect.Value {
+			results := make([]reflect.Value, v.Type().NumOut())
+			for i := range results {
+				results[i] = reflect.Zero(v.Type().Out(i))
+			}
+			return results
+		}))
+	case reflect.Chan:
+		v.Set(reflect.MakeChan(reflect.ChanOf(reflect.BothDir, v.Type().Elem()), 0).Convert(v.Type()))
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fill(t, settable(v.Field(i)))
+		}
+	case reflect.Interface:
+		for _, candidate := range []interface{}{errors.New("x"), &fakeNotifier{}, workerPlugin{}, reflect.TypeOf(0)} {
+			if reflect.TypeOf(candidate).Implements(v.Type()) {
+				v.Set(reflect.ValueOf(candidate))
+				return
+			}
+		}
+		require.Fail(t, "no value implements the interface", v.Type().String())
+	default:
+		require.Fail(t, "unsupported kind", v.Kind().String())
+	}
+}
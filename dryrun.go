@@ -0,0 +1,22 @@
+package logrus_bugsnag
+
+import (
+	"log"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+)
+
+// logDryRun logs the event a hook created with WithDryRun would have sent.
+func (hook *bugsnagHook) logDryRun(event *FinalizedEvent) {
+	class := event.Error.TypeName()
+	for _, datum := range event.RawData {
+		if errorClass, ok := datum.(bugsnag.ErrorClass); ok {
+			class = errorClass.Name
+		}
+	}
+	logf := log.Printf
+	if _, base := hook.notifiers(); base != nil && base.Config.Logger != nil {
+		logf = base.Config.Logger.Printf
+	}
+	logf("logrus_bugsnag: dry run of hook %q: %s: %s", hook.name, class, event.Error.Error())
+}
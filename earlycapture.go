@@ -0,0 +1,199 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// earlyCapture is a temporary hook recording the entries logged before the
+// Bugsnag hook could be created.
+type earlyCapture struct {
+	logger *logrus.Logger
+	max    int
+
+	mu      sync.Mutex
+	entries []*logrus.Entry
+	dropped int
+}
+
+var (
+	earlyCaptureMu sync.Mutex
+	// activeEarlyCapture is the installed early capture hook, if any.
+	activeEarlyCapture *earlyCapture
+)
+
+// InstallEarlyCapture registers a temporary hook on logger that records up to
+// max entries at the "Error" level or above, for errors logged before bugsnag
+// is configured. Once the Bugsnag hook is created, call its AdoptEarlyCapture
+// method to report them. When more than max entries are logged, the oldest
+// ones are dropped. If adoption never happens, the recorded entries are
+// simply never reported.
+//
+// Only one early capture can be installed at a time; InstallEarlyCapture does
+// nothing if one is already installed. As with Close, hooks must not be added
+// to or replaced on logger while AdoptEarlyCapture removes the temporary hook.
+func InstallEarlyCapture(logger *logrus.Logger, max int) {
+	earlyCaptureMu.Lock()
+	defer earlyCaptureMu.Unlock()
+	if activeEarlyCapture != nil || max <= 0 {
+		return
+	}
+	activeEarlyCapture = &earlyCapture{logger: logger, max: max}
+	logger.AddHook(activeEarlyCapture)
+}
+
+// Levels returns the levels recorded by the early capture hook.
+func (c *earlyCapture) Levels() []logrus.Level {
+	return defaultLevels
+}
+
+// earlyCallersKey is the context key of the stack trace captured when an
+// early entry was logged.
+type earlyCallersKey struct{}
+
+// earlyCallers returns the stack trace captured when entry was logged, if it
+// was recorded by the early capture hook.
+func earlyCallers(entry *logrus.Entry) ([]uintptr, bool) {
+	if entry.Context == nil {
+		return nil, false
+	}
+	callers, ok := entry.Context.Value(earlyCallersKey{}).([]uintptr)
+	return callers, ok
+}
+
+// Fire records a copy of the entry, as the caller may reuse it. Unless its
+// error carries its own stack trace, the stack trace of the logging call is
+// recorded with it, so that the entry is reported from where it was logged
+// rather than from AdoptEarlyCapture.
+func (c *earlyCapture) Fire(entry *logrus.Entry) error {
+	recorded := &logrus.Entry{
+		Logger:  entry.Logger,
+		Data:    deepCopyFields(entry.Data),
+		Time:    entry.Time,
+		Level:   entry.Level,
+		Message: entry.Message,
+		Context: entry.Context,
+	}
+	err, ok := entry.Data["error"].(error)
+	if !ok {
+		err = errors.New(entry.Message)
+	}
+	if _, ok := err.(interface{ Callers() []uintptr }); !ok {
+		ctx := recorded.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		recorded.Context = context.WithValue(ctx, earlyCallersKey{}, bugsnag_errors.New(err, 0).Callers())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) == c.max {
+		c.entries = c.entries[1:]
+		c.dropped++
+	}
+	c.entries = append(c.entries, recorded)
+	return nil
+}
+
+// uninstall removes the early capture hook from its logger.
+func (c *earlyCapture) uninstall() {
+	removeHook(c.logger, c)
+}
+
+// AdoptEarlyCapture reports the entries recorded by InstallEarlyCapture
+// through this hook and uninstalls the early capture hook. Reported entries
+// carry a "reported_late" field, their original time as "logged_at" and, if
+// entries were dropped, the number of dropped entries as
+// "early_capture_dropped". Their stack traces start where they were logged.
+// It returns the last error returned by Fire, if
+// any, and does nothing if no early capture is installed.
+func (hook *bugsnagHook) AdoptEarlyCapture() error {
+	earlyCaptureMu.Lock()
+	c := activeEarlyCapture
+	activeEarlyCapture = nil
+	earlyCaptureMu.Unlock()
+	if c == nil {
+		return nil
+	}
+	c.uninstall()
+
+	c.mu.Lock()
+	entries, dropped := c.entries, c.dropped
+	c.entries = nil
+	c.mu.Unlock()
+
+	var err error
+	for _, entry := range entries {
+		entry.Data["reported_late"] = true
+		entry.Data["logged_at"] = entry.Time.UTC().Format(time.RFC3339Nano)
+		if dropped > 0 {
+			entry.Data["early_capture_dropped"] = dropped
+		}
+		if fireErr := hook.Fire(entry); fireErr != nil {
+			err = fireErr
+		}
+	}
+	return err
+}
+
+// deepCopyFields copies fields and the maps and slices nested in them.
+func deepCopyFields(fields logrus.Fields) logrus.Fields {
+	c := make(logrus.Fields, len(fields))
+	for key, val := range fields {
+		c[key] = deepCopyValue(val)
+	}
+	return c
+}
+
+func deepCopyValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case logrus.Fields:
+		return deepCopyFields(v)
+	case map[string]interface{}:
+		return map[string]interface{}(deepCopyFields(v))
+	case nil, string, bool, int, int64, float64, error:
+		return val
+	}
+	return deepCopyReflect(reflect.ValueOf(val)).Interface()
+}
+
+// deepCopyReflect copies v if it is a map or a slice, along with the maps and
+// slices nested in it. Other values, such as pointers, are not copied.
+func deepCopyReflect(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopyReflect(iter.Value()))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyReflect(v.Index(i)))
+		}
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopyReflect(v.Elem()))
+		return c
+	}
+	return v
+}
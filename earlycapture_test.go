@@ -0,0 +1,69 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"io/ioutil"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
+)
+
+func TestEarlyCapture(t *testing.T) {
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	InstallEarlyCapture(log, 2)
+
+	nested := map[string]interface{}{"attempt": 1}
+	log.WithField("step", "config").Error("dropped")
+	log.WithFields(logrus.Fields{
+		"error":  errors.New("database unreachable"),
+		"nested": nested,
+	}).Error("early")
+	log.Warn("not recorded")
+	_, _, line, _ := runtime.Caller(0)
+	log.Error("secrets unavailable")
+	// The recorded entries must not change with the caller's data.
+	nested["attempt"] = 2
+
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	configureBugsnag(c.Endpoints())
+	hook, err := NewBugsnagHook()
+	require.NoError(t, err, "failed to create hook")
+	log.Hooks.Add(hook)
+
+	start := time.Now()
+	require.NoError(t, hook.AdoptEarlyCapture())
+
+	event := receiveEvent(t, c)
+	assert.Equal(t, "database unreachable", event.Exceptions[0].Message)
+	assert.Equal(t, true, event.Metadata["metadata"]["reported_late"])
+	assert.Equal(t, float64(1), event.Metadata["metadata"]["early_capture_dropped"])
+	assert.Equal(t, map[string]interface{}{"attempt": float64(1)}, event.Metadata["metadata"]["nested"])
+	loggedAt, err := time.Parse(time.RFC3339Nano, event.Metadata["metadata"]["logged_at"].(string))
+	require.NoError(t, err)
+	assert.True(t, loggedAt.Before(start))
+
+	event = receiveEvent(t, c)
+	assert.Equal(t, "secrets unavailable", event.Exceptions[0].Message)
+	// The stack trace starts where the entry was logged, not where it was
+	// adopted.
+	assert.Equal(t, "TestEarlyCapture", event.Exceptions[0].Stacktrace[0].Method)
+	assert.Equal(t, line+1, event.Exceptions[0].Stacktrace[0].LineNumber)
+
+	// The temporary hook is gone, so new entries are only reported once.
+	log.Error("after adoption")
+	event = receiveEvent(t, c)
+	assert.Equal(t, "after adoption", event.Exceptions[0].Message)
+	assert.NotContains(t, event.Metadata["metadata"], "reported_late")
+	assert.Empty(t, c.Pending())
+
+	// Adopting again does nothing.
+	assert.NoError(t, hook.AdoptEarlyCapture())
+	assert.Empty(t, c.Pending())
+}
@@ -0,0 +1,32 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"regexp"
+)
+
+// ErrorClasser is implemented by errors setting the class Bugsnag groups
+// them by, such as domain errors whose Go type is not meaningful.
+type ErrorClasser interface {
+	ErrorClass() string
+}
+
+// packageQualifier matches the package names qualifying the types in the
+// name of a Go type, such as "errors." in "*errors.ValidationError".
+var packageQualifier = regexp.MustCompile(`[\p{L}_][\p{L}\p{N}_]*\.`)
+
+// errorClass returns the class reported for err, whose Go type is named
+// typeName: the class set by err or by an error it wraps, if any, or else
+// typeName.
+func (hook *bugsnagHook) errorClass(err error, typeName string) string {
+	var classer ErrorClasser
+	if errors.As(err, &classer) {
+		if class := classer.ErrorClass(); class != "" {
+			return hook.errorClassPrefix + class
+		}
+	}
+	if hook.shortErrorClass {
+		typeName = packageQualifier.ReplaceAllString(typeName, "")
+	}
+	return hook.errorClassPrefix + typeName
+}
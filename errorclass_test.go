@@ -0,0 +1,79 @@
+package logrus_bugsnag
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type validationError struct{}
+
+func (*validationError) Error() string {
+	return "invalid order"
+}
+
+func TestErrorClass(t *testing.T) {
+	for _, tt := range []struct {
+		opts  []Option
+		class string
+	}{
+		{nil, "*logrus_bugsnag.validationError"},
+		{[]Option{WithShortErrorClass(true)}, "*validationError"},
+		{[]Option{WithErrorClassPrefix("checkout: ")}, "checkout: *logrus_bugsnag.validationError"},
+		{[]Option{WithShortErrorClass(true), WithErrorClassPrefix("checkout: ")}, "checkout: *validationError"},
+		{[]Option{WithShortErrorClass(true), WithTitleCaseMessages(true)}, "*validationError"},
+		{[]Option{WithShortErrorClass(true), WithMinimalMode(func(*logrus.Entry) bool { return true })}, "*validationError"},
+	} {
+		log, _, c, teardown := newTestLogger(t, tt.opts...)
+		log.WithError(&validationError{}).Error("failed")
+		assert.Equal(t, tt.class, receiveEvent(t, c).Exceptions[0].ErrorClass)
+		teardown()
+	}
+}
+
+func TestShortErrorClass(t *testing.T) {
+	hook := &bugsnagHook{shortErrorClass: true}
+	for typeName, class := range map[string]string{
+		"*errors.errorString":                "*errorString",
+		"map[string]*errors.ValidationError": "map[string]*ValidationError",
+		"[]net.Error":                        "[]Error",
+		"errors.Set[errors.Key]":             "Set[Key]",
+		"myError":                            "myError",
+	} {
+		assert.Equal(t, class, hook.errorClass(nil, typeName))
+	}
+}
+
+// paymentDeclined sets its error class.
+type paymentDeclined struct {
+	code string
+}
+
+func (e paymentDeclined) Error() string {
+	return "payment declined: " + e.code
+}
+
+func (e paymentDeclined) ErrorClass() string {
+	return "PaymentDeclined"
+}
+
+func TestErrorClasser(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t)
+	defer teardown()
+
+	log.WithError(paymentDeclined{"insufficient_funds"}).Error("checkout failed")
+	event := receiveEvent(t, c)
+	assert.Equal(t, "PaymentDeclined", event.Exceptions[0].ErrorClass)
+	assert.Equal(t, "payment declined: insufficient_funds", event.Exceptions[0].Message)
+
+	// The class of wrapped errors is used too.
+	log.WithError(fmt.Errorf("checkout: %w", paymentDeclined{"expired_card"})).Error("checkout failed")
+	assert.Equal(t, "PaymentDeclined", receiveEvent(t, c).Exceptions[0].ErrorClass)
+
+	log, _, c, teardown = newTestLogger(t, WithErrorClassPrefix("checkout: "), WithShortErrorClass(true))
+	defer teardown()
+	log.WithError(paymentDeclined{"expired_card"}).Error("checkout failed")
+	assert.Equal(t, "checkout: PaymentDeclined", receiveEvent(t, c).Exceptions[0].ErrorClass)
+}
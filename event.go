@@ -0,0 +1,317 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrEventIgnored is returned by EventBuilder.Build for events the hook does
+// not report, such as context cancellations.
+var ErrEventIgnored = errors.New("the hook does not report this event")
+
+// FinalizedEvent is an event ready to be sent to Bugsnag, as Fire would send
+// it.
+type FinalizedEvent struct {
+	// Error is the reported error, with its stack trace.
+	Error *bugsnag_errors.Error
+	// RawData is passed to bugsnag along with Error: the metadata, and the
+	// severity, error class and grouping hash if they are set.
+	RawData []interface{}
+
+	// tabSizes is the estimated size of each tab of the metadata.
+	tabSizes map[string]int
+}
+
+// Metadata returns the metadata of the event.
+func (e *FinalizedEvent) Metadata() bugsnag.MetaData {
+	for _, datum := range e.RawData {
+		if metadata, ok := datum.(bugsnag.MetaData); ok {
+			return metadata
+		}
+	}
+	return nil
+}
+
+// Notify sends the event with n.
+func (e *FinalizedEvent) Notify(n Notifier) error {
+	return n.Notify(e.Error, e.RawData...)
+}
+
+// EventBuilder builds the event the hook would send for a log entry, without
+// a logger. Tools replaying errors use it so that their events cannot drift
+// from the hook's.
+type EventBuilder struct {
+	hook  *bugsnagHook
+	entry *logrus.Entry
+}
+
+// NewEventBuilder returns a builder of events configured like the hook. The
+// level of the events defaults to "Error".
+func (hook *bugsnagHook) NewEventBuilder() *EventBuilder {
+	entry := logrus.NewEntry(logrus.StandardLogger())
+	entry.Level = logrus.ErrorLevel
+	return &EventBuilder{hook: hook, entry: entry}
+}
+
+// WithError sets the error of the event, like the "error" field of an entry.
+func (b *EventBuilder) WithError(err error) *EventBuilder {
+	b.entry = b.entry.WithField(logrus.ErrorKey, err)
+	return b
+}
+
+// WithMessage sets the message of the entry.
+func (b *EventBuilder) WithMessage(message string) *EventBuilder {
+	b.entry.Message = message
+	return b
+}
+
+// WithFields adds fields to the entry.
+func (b *EventBuilder) WithFields(fields logrus.Fields) *EventBuilder {
+	b.entry = b.entry.WithFields(fields)
+	return b
+}
+
+// WithLevel sets the level of the entry.
+func (b *EventBuilder) WithLevel(level logrus.Level) *EventBuilder {
+	b.entry.Level = level
+	return b
+}
+
+// WithContext sets the context of the entry.
+func (b *EventBuilder) WithContext(ctx context.Context) *EventBuilder {
+	b.entry.Context = ctx
+	return b
+}
+
+// Build returns the event. The stack trace of the event starts at the caller
+// of Build. Rate limiting, deduplication and the circuit breaker do not apply.
+// It returns ErrEventIgnored if the hook would not report the entry.
+func (b *EventBuilder) Build() (*FinalizedEvent, error) {
+	b.entry.Time = time.Now()
+	event, outcome := b.hook.finalize(b.entry, false)
+	if outcome != eventReady {
+		return nil, ErrEventIgnored
+	}
+	return event, nil
+}
+
+// eventOutcome is the outcome of the finalization of an event.
+type eventOutcome int
+
+const (
+	eventReady eventOutcome = iota
+	// eventIgnored events are deliberately not reported.
+	eventIgnored
+	// eventDropped events are suppressed by the rate limit, deduplication or
+	// the circuit breaker.
+	eventDropped
+)
+
+// finalize builds the event reporting entry. Events are subject to rate
+// limiting, deduplication and the circuit breaker if live is set.
+func (hook *bugsnagHook) finalize(entry *logrus.Entry, live bool) (*FinalizedEvent, eventOutcome) {
+	var notifyErr error
+	err, ok := entry.Data["error"].(error)
+	if val, isPanic := hook.panicValue(entry); isPanic {
+		if panicErr, isErr := val.(error); isErr {
+			err, ok = panicErr, true
+		}
+	}
+	combinable := ok && entry.Message != ""
+	var rest string
+	var fromMessage bool
+	if !ok {
+		err, rest, fromMessage = hook.extractError(entry.Message)
+		ok = fromMessage
+	}
+	if ok {
+		if hook.ignorable(err) || hook.suppressedStatus(err) || hook.ignoredError(err) {
+			return nil, eventIgnored
+		}
+		notifyErr = err
+	} else {
+		notifyErr = errors.New(entry.Message)
+	}
+	if hook.ignoredByFunc(entry, notifyErr) || !hook.notifiesReleaseStage(entry) {
+		return nil, eventIgnored
+	}
+
+	if live && hook.suppressFeature != nil && hook.suppressFeature(entry) {
+		return nil, eventIgnored
+	}
+	if live && hook.approve != nil {
+		ctx := entry.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if !hook.approve(ctx, notifyErr, entry) {
+			return nil, eventIgnored
+		}
+	}
+	sampleRate := 1.0
+	if live && hook.sampler != nil {
+		var sampled bool
+		if sampled, sampleRate = hook.sampler.sample(entry, notifyErr); !sampled {
+			return nil, eventIgnored
+		}
+	}
+
+	if hook.minimalMatcher != nil && hook.minimalMatcher(entry) {
+		return hook.finalizeMinimal(entry, notifyErr), eventReady
+	}
+
+	captured := bugsnag_errors.New(notifyErr, 0)
+	skipStackFrames := CalcSkipStackFrames(captured, hook.skipPackages)
+	errWithStack := bugsnag_errors.New(notifyErr, skipStackFrames)
+	if callers, early := earlyCallers(entry); early {
+		// The stack trace was captured by the early capture hook when the
+		// entry was logged.
+		captured = withCallers(captured, callers)
+		errWithStack = atCaller(captured, withCallers(captured, callers[CalcSkipStackFrames(captured, hook.skipPackages):]), entry)
+	} else if _, ok := notifyErr.(interface{ Callers() []uintptr }); !ok {
+		// The stack trace was captured by the hook rather than by the
+		// error.
+		errWithStack = atCaller(captured, errWithStack, entry)
+	}
+	if callers := errWithStack.Callers(); hook.maxStackDepth > 0 && len(callers) > hook.maxStackDepth {
+		errWithStack = withCallers(errWithStack, callers[:hook.maxStackDepth])
+	}
+
+	var duplicates, rateLimited int
+	release := func() {}
+	if live && hook.limiter != nil {
+		var allowed bool
+		allowed, duplicates, rateLimited, release = hook.limiter.allow(dedupKey(errWithStack), time.Now())
+		if !allowed {
+			return nil, eventDropped
+		}
+	}
+
+	if live && hook.distributedDedup != nil && hook.sentElsewhere(entry, errWithStack) {
+		release()
+		return nil, eventDropped
+	}
+
+	if live && hook.breaker != nil && !hook.breaker.allow(time.Now()) {
+		release()
+		return nil, eventDropped
+	}
+
+	metadata := hook.buildMetadata(entry)
+	hook.addErrorMetadata(metadata, notifyErr)
+	if fromMessage && rest != "" {
+		mergeTab(metadata, logrusTab, map[string]interface{}{"message": rest})
+	}
+	hook.addFeatureFlags(metadata, entry)
+	hook.addTags(metadata, entry)
+	hook.addRequestID(metadata, entry)
+	hook.addPanicValue(metadata, entry)
+	if hook.logMessageField != "" {
+		metadata[metadataTab][hook.logMessageField] = entry.Message
+	}
+	if hook.sampler != nil {
+		metadata[metadataTab]["sample_rate"] = sampleRate
+	}
+	if hook.timestampField != "" {
+		metadata[metadataTab][hook.timestampField] = entry.Time.UTC().Format(time.RFC3339Nano)
+	}
+	if hook.errorChain {
+		if chain := errorChain(notifyErr); chain != nil {
+			metadata[metadataTab]["error_chain"] = chain
+		}
+	}
+	if len(hook.errorChainTypes) > 0 {
+		hook.addErrorChainTypes(metadata, notifyErr)
+	}
+	if hook.multiErrorMode == MultiErrorGrouped {
+		hook.addMultiErrors(metadata, notifyErr)
+	}
+	if duplicates > 0 {
+		metadata[metadataTab]["suppressed_duplicates"] = duplicates
+	}
+	if rateLimited > 0 {
+		metadata[metadataTab]["suppressed_rate_limited"] = rateLimited
+	}
+
+	typeName := errWithStack.TypeName()
+	errorClass := hook.errorClass(notifyErr, typeName)
+	hook.addRunbook(metadata, entry, notifyErr, errorClass)
+	for tab, fields := range hook.staticTabs {
+		mergeTab(metadata, tab, copyMap(fields))
+	}
+	if hook.breadcrumbs != nil {
+		if crumbs := hook.breadcrumbsTab(); crumbs != nil {
+			metadata[breadcrumbsTab] = crumbs
+		}
+	}
+	if len(hook.plugins) > 0 {
+		metadata = hook.enrich(entry, metadata)
+	}
+	rawData := []interface{}{metadata}
+	switch {
+	case entry.Level == logrus.WarnLevel:
+		rawData = append(rawData, bugsnag.SeverityWarning)
+	case entry.Level > logrus.WarnLevel:
+		// A forced entry.
+		rawData = append(rawData, bugsnag.SeverityInfo)
+	}
+	if state, unhandled := hook.unhandledState(entry.Level); unhandled {
+		// The handled state sets the severity of the event too.
+		rawData = append(rawData, state)
+	}
+	if config, ok := hook.releaseOverride(entry); ok {
+		rawData = append(rawData, config)
+	}
+	if len(hook.fingerprintFields) > 0 {
+		if hash := hook.fingerprint(entry); hash != "" {
+			rawData = append(rawData, hash)
+		}
+	}
+	if hook.beforeNotify != nil {
+		// The callback may run after the caller reused the entry.
+		snapshot := &logrus.Entry{
+			Logger:  entry.Logger,
+			Data:    logrus.Fields(copyMap(entry.Data)),
+			Time:    entry.Time,
+			Level:   entry.Level,
+			Message: entry.Message,
+			Context: entry.Context,
+		}
+		rawData = append(rawData, entryCallback{hook.beforeNotify, snapshot})
+	}
+	marshaled := false
+	if hook.textMarshalerErrors {
+		if text, ok := marshaledText(notifyErr); ok {
+			message := errWithStack.Error()
+			mergeTab(metadata, errorTab, map[string]interface{}{"go_error": message})
+			errWithStack.Err = marshaledError{errWithStack.Err, text}
+			marshaled = true
+		}
+	}
+	combined := false
+	if hook.combineMessages && combinable && entry.Message != errWithStack.Error() {
+		errWithStack.Err = combinedError{errWithStack.Err, entry.Message}
+		combined = true
+	}
+	titled := false
+	if hook.titleCase {
+		message := errWithStack.Error()
+		if title := titleCase(message); title != message {
+			mergeTab(metadata, logrusTab, map[string]interface{}{"original_message": message})
+			errWithStack.Err = titledError{errWithStack.Err, title}
+			titled = true
+		}
+	}
+	if marshaled || combined || titled || errorClass != typeName {
+		// Keep the class of the original error.
+		rawData = append(rawData, bugsnag.ErrorClass{Name: errorClass})
+	}
+	// Applied last, for the limits to cover every tab.
+	tabSizes := hook.limits.apply(metadata)
+	return &FinalizedEvent{Error: errWithStack, RawData: rawData, tabSizes: tabSizes}, eventReady
+}
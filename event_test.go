@@ -0,0 +1,68 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBuilderMatchesFire(t *testing.T) {
+	opts := []Option{
+		WithRedactedFields("card"),
+		WithTabPromotion(true),
+		WithTitleCaseMessages(true),
+		WithRunbookURL(func(error) string { return "https://runbooks.example.com/orders" }),
+	}
+	fields := logrus.Fields{
+		"order_id":    42,
+		"card_number": "4242424242424242",
+		"request":     map[string]interface{}{"path": "/orders"},
+	}
+	err := errors.New("payment declined")
+
+	log, hook, notifier := newFakeLogger(t, opts...)
+	log.WithFields(fields).WithError(err).Warn("failed to process order")
+	log.WithFields(fields).WithError(err).Error("failed to process order")
+	calls := notifier.sent()
+	require.Len(t, calls, 1, "warnings are not reported")
+
+	event, buildErr := hook.NewEventBuilder().
+		WithError(err).
+		WithMessage("failed to process order").
+		WithFields(fields).
+		WithLevel(logrus.ErrorLevel).
+		WithContext(context.Background()).
+		Build()
+	require.NoError(t, buildErr)
+
+	assert.Equal(t, calls[0].err.Error(), event.Error.Error())
+	assert.Equal(t, calls[0].err.(interface{ TypeName() string }).TypeName(), event.Error.TypeName())
+	assert.Equal(t, calls[0].rawData, event.RawData)
+	assert.Equal(t, "[REDACTED]", event.Metadata()["metadata"]["card_number"])
+	// The stack trace starts at the caller in both cases.
+	assert.Equal(t, "TestEventBuilderMatchesFire", event.Error.StackFrames()[0].Name)
+
+	// The event can be sent as Fire would.
+	require.NoError(t, event.Notify(notifier))
+	assert.Equal(t, []notifyCall{{err: event.Error, rawData: event.RawData}}, notifier.sent())
+}
+
+func TestEventBuilderWarning(t *testing.T) {
+	_, hook, _ := newFakeLogger(t)
+	event, err := hook.NewEventBuilder().WithMessage("disk almost full").WithLevel(logrus.WarnLevel).Build()
+	require.NoError(t, err)
+	assert.Equal(t, "disk almost full", event.Error.Error())
+	assert.Contains(t, event.RawData, bugsnag.SeverityWarning)
+}
+
+func TestEventBuilderIgnored(t *testing.T) {
+	_, hook, _ := newFakeLogger(t)
+	event, err := hook.NewEventBuilder().WithError(context.Canceled).Build()
+	assert.Nil(t, event)
+	assert.Equal(t, ErrEventIgnored, err)
+}
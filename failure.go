@@ -0,0 +1,99 @@
+package logrus_bugsnag
+
+import (
+	"fmt"
+	"log"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+)
+
+// hookFailureClass is the error class of the event reporting the failure of
+// a hook.
+const hookFailureClass = "logrus_bugsnag.HookFailure"
+
+// Failure returns the failure of the hook's own machinery, such as a panic
+// during a background delivery, or nil if there was none.
+//
+// After such a failure, the hook degrades to a pass-through mode: every event
+// is delivered synchronously from Fire, without retries, batching,
+// compression nor mirror. A single event describing the failure is sent to
+// Bugsnag. The hook stays degraded until RefreshConfig is called or the hook
+// is created again. The failure is also reported by Status, and logged with
+// the logger of the bugsnag configuration.
+func (hook *bugsnagHook) Failure() error {
+	hook.mu.RLock()
+	defer hook.mu.RUnlock()
+	return hook.failure
+}
+
+// degraded returns the notifier delivering the events of a degraded hook, or
+// nil if the hook is not degraded.
+func (hook *bugsnagHook) degraded() Notifier {
+	hook.mu.RLock()
+	defer hook.mu.RUnlock()
+	if hook.failure == nil {
+		return nil
+	}
+	return hook.simpleNotifier()
+}
+
+// simpleNotifier returns the notifier with the simplest delivery path, or nil
+// if there is none. hook.mu must be held.
+func (hook *bugsnagHook) simpleNotifier() Notifier {
+	if hook.sender != nil {
+		return hook.sender
+	}
+	if base := hook.lockedBase(); base != nil {
+		return synchronous{base}
+	}
+	return nil
+}
+
+// lockedBase returns the base notifier of the hook, which is that of its
+// parent for a derived hook. hook.mu must be held.
+func (hook *bugsnagHook) lockedBase() *bugsnag.Notifier {
+	if hook.parent != nil {
+		_, base := hook.parent.notifiers()
+		return base
+	}
+	return hook.base
+}
+
+// fail degrades the hook after a failure of its machinery and reports the
+// failure, unless the hook is already degraded.
+func (hook *bugsnagHook) fail(cause error) {
+	hook.mu.Lock()
+	if hook.failure != nil {
+		hook.mu.Unlock()
+		return
+	}
+	hook.failure = cause
+	notifier, base := hook.simpleNotifier(), hook.lockedBase()
+	hook.mu.Unlock()
+
+	logf := log.Printf
+	if base != nil && base.Config.Logger != nil {
+		logf = base.Config.Logger.Printf
+	}
+	logf("logrus_bugsnag: hook %q degraded to synchronous deliveries: %v", hook.name, cause)
+	if notifier == nil {
+		return
+	}
+	defer func() {
+		// The report must not fail like the deliveries did.
+		if r := recover(); r != nil {
+			logf("logrus_bugsnag: failed to report the failure of hook %q: %v", hook.name, r)
+		}
+	}()
+	err := fmt.Errorf("logrus_bugsnag hook %q failed: %v", hook.name, cause)
+	if sendErr := notifier.Notify(err, bugsnag.ErrorClass{Name: hookFailureClass}); sendErr != nil {
+		logf("logrus_bugsnag: failed to report the failure of hook %q: %v", hook.name, sendErr)
+	}
+}
+
+// restore restores the normal operation of a degraded hook.
+func (hook *bugsnagHook) restore() {
+	hook.mu.Lock()
+	hook.failure = nil
+	hook.mu.Unlock()
+}
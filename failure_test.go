@@ -0,0 +1,95 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
+)
+
+// recordingLogger records the messages bugsnag and the hook log.
+type recordingLogger struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.msgs = append(l.msgs, fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) messages() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.msgs...)
+}
+
+// panickyTransport panics on its first request, as a corrupted delivery
+// would, and sends the others.
+type panickyTransport struct {
+	panicked atomic.Bool
+}
+
+func (t *panickyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.panicked.CompareAndSwap(false, true) {
+		panic("worker loop corrupted")
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestFailureDegradesHook(t *testing.T) {
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	logger := &recordingLogger{}
+	notifier := bugsnag.New(bugsnag.Configuration{
+		APIKey:    bugsnagtest.APIKey,
+		Endpoints: c.Endpoints(),
+		Transport: &panickyTransport{},
+		Logger:    logger,
+	})
+	notifier.Config.Synchronous = false
+	hook, err := NewBugsnagHookWithNotifier(notifier, WithRegistry(nil), WithName("orders"))
+	require.NoError(t, err)
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+	assert.Equal(t, Status{Name: "orders", Enabled: true}, hook.Status())
+
+	log.Error("boom")
+	require.NoError(t, hook.Flush(context.Background()))
+	event := receiveEvent(t, c)
+	assert.Equal(t, hookFailureClass, event.Exceptions[0].ErrorClass)
+	assert.Contains(t, event.Exceptions[0].Message, "worker loop corrupted")
+	require.Error(t, hook.Failure())
+	assert.Contains(t, hook.Failure().Error(), "background delivery panicked")
+	status := hook.Status()
+	assert.True(t, status.Degraded)
+	assert.Equal(t, hook.Failure(), status.Failure)
+	assert.Contains(t, strings.Join(logger.messages(), "\n"), `hook "orders" degraded`)
+	assert.Equal(t, Stats{Attempted: 1, Failed: 1}, counters(hook.Stats()))
+
+	// Degraded hooks deliver synchronously.
+	log.Error("after")
+	assert.Equal(t, Stats{Attempted: 2, Sent: 1, Failed: 1}, counters(hook.Stats()))
+	assert.Equal(t, "after", receiveEvent(t, c).Exceptions[0].Message)
+	assert.Empty(t, c.Pending())
+
+	// RefreshConfig restores asynchronous deliveries.
+	require.NoError(t, hook.RefreshConfig())
+	assert.NoError(t, hook.Failure())
+	assert.False(t, hook.Status().Degraded)
+	log.Error("async again")
+	require.NoError(t, hook.Flush(context.Background()))
+	assert.Equal(t, "async again", receiveEvent(t, c).Exceptions[0].Message)
+}
@@ -0,0 +1,58 @@
+package logrus_bugsnag
+
+import (
+	"fmt"
+	"strings"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	featureFlagsTab = "featureFlags"
+
+	defaultFeatureFlagPrefix = "feature:"
+)
+
+// FeatureFlag is a feature flag active when an entry was logged, with the
+// variant of the flag in use.
+type FeatureFlag struct {
+	Name    string
+	Variant string
+}
+
+// isFeatureFlagField reports whether the entry field with the given name
+// holds a feature flag.
+func (hook *bugsnagHook) isFeatureFlagField(key string) bool {
+	return hook.featureFlagPrefix != "" && strings.HasPrefix(key, hook.featureFlagPrefix) &&
+		len(key) > len(hook.featureFlagPrefix)
+}
+
+// featureFlags returns the feature flags set by the entry's fields.
+func (hook *bugsnagHook) featureFlags(entry *logrus.Entry) []FeatureFlag {
+	var flags []FeatureFlag
+	for key, val := range entry.Data {
+		if !hook.isFeatureFlagField(key) {
+			continue
+		}
+		flags = append(flags, FeatureFlag{
+			Name:    strings.TrimPrefix(key, hook.featureFlagPrefix),
+			Variant: fmt.Sprint(val),
+		})
+	}
+	return flags
+}
+
+// addFeatureFlags adds the feature flags set by the entry's fields to their
+// own tab, as bugsnag-go v1 events have no feature flags.
+func (hook *bugsnagHook) addFeatureFlags(metadata bugsnag.MetaData, entry *logrus.Entry) {
+	flags := hook.featureFlags(entry)
+	if len(flags) == 0 {
+		return
+	}
+	tab := make(map[string]interface{}, len(flags))
+	for _, flag := range flags {
+		tab[flag.Name] = flag.Variant
+	}
+	metadata[featureFlagsTab] = tab
+}
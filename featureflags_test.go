@@ -0,0 +1,58 @@
+package logrus_bugsnag
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t)
+	defer teardown()
+
+	log.WithFields(logrus.Fields{
+		"feature:new_checkout": "enabled",
+		"feature:beta":         true,
+		"animal":               "walrus",
+	}).Error("checkout failed")
+	event := receiveEvent(t, c)
+	assert.Equal(t, map[string]interface{}{"new_checkout": "enabled", "beta": "true"}, event.Metadata[featureFlagsTab])
+	assert.Equal(t, map[string]interface{}{"animal": "walrus"}, event.Metadata["metadata"])
+}
+
+func TestWithFeatureFlagPrefix(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithFeatureFlagPrefix("ff."))
+	defer teardown()
+
+	log.WithFields(logrus.Fields{"ff.new_checkout": "control", "feature:beta": "on"}).Error("checkout failed")
+	event := receiveEvent(t, c)
+	assert.Equal(t, map[string]interface{}{"new_checkout": "control"}, event.Metadata[featureFlagsTab])
+	assert.Equal(t, map[string]interface{}{"feature:beta": "on"}, event.Metadata["metadata"])
+}
+
+func TestFeatureFlagsDisabled(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithFeatureFlagPrefix(""))
+	defer teardown()
+
+	log.WithField("feature:beta", "on").Error("checkout failed")
+	event := receiveEvent(t, c)
+	assert.NotContains(t, event.Metadata, featureFlagsTab)
+	assert.Equal(t, map[string]interface{}{"feature:beta": "on"}, event.Metadata["metadata"])
+}
+
+func TestWithFeatureFlagSuppressor(t *testing.T) {
+	enabled := map[string]bool{"new_checkout_flow": false, "new_search": true}
+	suppress := func(entry *logrus.Entry) bool {
+		flag, ok := entry.Data["feature_flag"].(string)
+		return ok && !enabled[flag]
+	}
+	log, hook, notifier := newFakeLogger(t, WithFeatureFlagSuppressor(suppress))
+
+	log.WithField("feature_flag", "new_checkout_flow").Error("checkout failed")
+	log.WithField("feature_flag", "new_search").Error("search failed")
+	log.Error("payment failed")
+
+	assert.Equal(t, []string{"search failed", "payment failed"}, messagesOf(notifier.sent()))
+	assert.Equal(t, Stats{Attempted: 3, Sent: 2, Ignored: 1}, counters(hook.Stats()))
+}
@@ -0,0 +1,28 @@
+package logrus_bugsnag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fingerprint returns the grouping hash of the entry computed from the values
+// of the fingerprint fields, or "" if the entry has none of them.
+func (hook *bugsnagHook) fingerprint(entry *logrus.Entry) groupingHash {
+	h := sha256.New()
+	found := false
+	for _, key := range hook.fingerprintFields {
+		val, ok := entry.Data[key]
+		if !ok {
+			continue
+		}
+		found = true
+		fmt.Fprintf(h, "%s=%v\x00", key, val)
+	}
+	if !found {
+		return ""
+	}
+	return groupingHash(hex.EncodeToString(h.Sum(nil))[:16])
+}
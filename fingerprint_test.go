@@ -0,0 +1,42 @@
+package logrus_bugsnag
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// groupingHashOf returns the grouping hash sent with the call, if any.
+func groupingHashOf(call notifyCall) groupingHash {
+	for _, datum := range call.rawData {
+		if hash, ok := datum.(groupingHash); ok {
+			return hash
+		}
+	}
+	return ""
+}
+
+func TestFingerprintFields(t *testing.T) {
+	log, _, notifier := newFakeLogger(t, WithFingerprintFields("operation", "error_code"))
+
+	log.WithFields(logrus.Fields{"operation": "charge", "error_code": 402, "order_id": 1}).Error("card 1 declined")
+	log.WithFields(logrus.Fields{"operation": "charge", "error_code": 402, "order_id": 2}).Error("card 2 declined")
+	log.WithFields(logrus.Fields{"operation": "charge", "error_code": 500}).Error("card 3 declined")
+	log.WithFields(logrus.Fields{"operation": "refund"}).Error("refund failed")
+	log.WithFields(logrus.Fields{"order_id": 3}).Error("no fingerprint")
+
+	calls := notifier.sent()
+	require.Len(t, calls, 5)
+	hashes := make([]groupingHash, len(calls))
+	for i, call := range calls {
+		hashes[i] = groupingHashOf(call)
+	}
+	assert.Len(t, string(hashes[0]), 16)
+	assert.Equal(t, hashes[0], hashes[1], "matching fields must group together")
+	assert.NotEqual(t, hashes[0], hashes[2])
+	assert.NotEmpty(t, hashes[3])
+	assert.NotEqual(t, hashes[2], hashes[3])
+	assert.Empty(t, hashes[4])
+}
@@ -0,0 +1,99 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+)
+
+// Flush waits until the events the hook is delivering in the background have
+// been sent. This is the case of all the events if the hook's configuration
+// is asynchronous (Synchronous is false), and of the events sent to the
+// mirror. Call Flush before the process exits so that these events are not
+// lost.
+//
+// If ctx is done first, Flush returns ctx.Err(), such as
+// context.DeadlineExceeded, and the remaining events keep being delivered in
+// the background.
+//
+// With WithBatching, Flush first sends the pending batch, and the events
+// added to a batch while it waits are sent at once. It returns the error
+// sending the pending batch, if any.
+func (hook *bugsnagHook) Flush(ctx context.Context) error {
+	if hook.batch == nil {
+		return hook.pending.wait(ctx)
+	}
+	defer hook.batch.drain()()
+	batchErr := hook.batch.flushSync(ctx)
+	if err := hook.pending.wait(ctx); err != nil {
+		return err
+	}
+	return batchErr
+}
+
+// deliverInBackground sends err with notifier without blocking, retrying
+// failures as configured with WithRetry. attempted is the number of attempts
+// that already failed. Flush waits for the delivery to complete. Its outcome
+// is passed to onDone, if not nil. A panic during the delivery degrades the
+// hook, as described in Failure.
+func (hook *bugsnagHook) deliverInBackground(notifier Notifier, err *bugsnag_errors.Error, rawData []interface{}, attempted int, onDone func(error)) {
+	hook.pending.add()
+	go func() {
+		defer hook.pending.done()
+		defer func() {
+			if r := recover(); r != nil {
+				hook.stats.failed.Add(1)
+				hook.fail(fmt.Errorf("background delivery panicked: %v", r))
+			}
+		}()
+		deliveryErr := hook.send(notifier, err, rawData, attempted)
+		if onDone != nil {
+			onDone(deliveryErr)
+		}
+	}()
+}
+
+// pendingTracker counts the deliveries running in the background.
+type pendingTracker struct {
+	mu    sync.Mutex
+	count int
+	// idle is closed when count drops to zero.
+	idle chan struct{}
+}
+
+func (p *pendingTracker) add() {
+	p.mu.Lock()
+	if p.count == 0 {
+		p.idle = make(chan struct{})
+	}
+	p.count++
+	p.mu.Unlock()
+}
+
+func (p *pendingTracker) done() {
+	p.mu.Lock()
+	p.count--
+	if p.count == 0 {
+		close(p.idle)
+	}
+	p.mu.Unlock()
+}
+
+func (p *pendingTracker) wait(ctx context.Context) error {
+	p.mu.Lock()
+	if p.count == 0 {
+		p.mu.Unlock()
+		return nil
+	}
+	idle := p.idle
+	p.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,55 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
+)
+
+func TestFlush(t *testing.T) {
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+
+	log, hook := newAsyncLogger(t, c.NotifyURL(), WithRegistry(nil))
+	for i := 0; i < 5; i++ {
+		log.Error(fmt.Sprintf("error %d", i))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	require.NoError(t, hook.Flush(ctx))
+	// All the events arrived before Flush returned.
+	events := c.Events()
+	require.Len(t, events, 5)
+	var messages []string
+	for _, event := range events {
+		messages = append(messages, event.Exceptions[0].Message)
+	}
+	assert.ElementsMatch(t, []string{"error 0", "error 1", "error 2", "error 3", "error 4"}, messages)
+
+	// Flushing an idle hook returns immediately.
+	require.NoError(t, hook.Flush(ctx))
+}
+
+func TestFlushDeadline(t *testing.T) {
+	unblock := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer hanging.Close()
+	defer close(unblock)
+
+	log, hook := newAsyncLogger(t, hanging.URL, WithRegistry(nil))
+	log.Error("never delivered")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, hook.Flush(ctx))
+}
@@ -0,0 +1,47 @@
+package logrus_bugsnag
+
+import (
+	"strings"
+	"time"
+
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// WrapGoroutine returns a function calling fn that recovers from its panics
+// and reports them to Bugsnag with hook, as the error of a "Panic" level entry
+// of logger. The stack trace of the event starts where fn panicked. The
+// panic does not propagate: the goroutine returns instead of crashing the
+// process. The returned function can be passed to go:
+//
+//	go logrus_bugsnag.WrapGoroutine(hook, log, work)()
+func WrapGoroutine(hook *bugsnagHook, logger *logrus.Logger, fn func()) func() {
+	return func() {
+		defer func() {
+			if r := recover(); r != nil {
+				hook.firePanic(logger, r)
+			}
+		}()
+		fn()
+	}
+}
+
+// firePanic fires a "Panic" level entry of logger reporting the recovered
+// panic value r. It must be called by the function deferred by the panicking
+// goroutine, whose stack holds the panicking frames.
+func (hook *bugsnagHook) firePanic(logger *logrus.Logger, r interface{}) {
+	captured := bugsnag_errors.New(r, 0)
+	callers := captured.Callers()
+	// The frames above runtime.gopanic are those of the deferred functions.
+	for i, frame := range captured.StackFrames() {
+		if frame.Package == "runtime" && strings.HasPrefix(frame.Name, "gopanic") && i+1 < len(callers) {
+			captured = withCallers(captured, callers[i+1:])
+			break
+		}
+	}
+	entry := logrus.NewEntry(logger).WithField(logrus.ErrorKey, captured)
+	entry.Level = logrus.PanicLevel
+	entry.Message = "goroutine panicked"
+	entry.Time = time.Now()
+	hook.Fire(entry)
+}
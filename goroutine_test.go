@@ -0,0 +1,45 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func panicking(value interface{}) {
+	panic(value)
+}
+
+func TestWrapGoroutine(t *testing.T) {
+	log, hook, c, teardown := newTestLogger(t)
+	defer teardown()
+
+	for _, value := range []interface{}{errors.New("nil map"), "boom"} {
+		value := value
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			WrapGoroutine(hook, log, func() { panicking(value) })()
+		}()
+		<-done
+	}
+
+	event := receiveEvent(t, c)
+	assert.Equal(t, "nil map", event.Exceptions[0].Message)
+	assert.Equal(t, "*errors.errorString", event.Exceptions[0].ErrorClass)
+	assert.True(t, event.Unhandled)
+	require.NotEmpty(t, event.Exceptions[0].Stacktrace)
+	assert.Equal(t, "panicking", event.Exceptions[0].Stacktrace[0].Method)
+
+	event = receiveEvent(t, c)
+	assert.Equal(t, "boom", event.Exceptions[0].Message)
+	assert.Equal(t, "panicking", event.Exceptions[0].Stacktrace[0].Method)
+
+	// Functions that do not panic are unaffected.
+	ran := false
+	WrapGoroutine(hook, log, func() { ran = true })()
+	assert.True(t, ran)
+	assert.Empty(t, c.Pending())
+}
@@ -0,0 +1,91 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+)
+
+// errorType is the type of the error interface.
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ignoredError reports whether err is, or wraps, one of the ignored errors or
+// an error of one of the ignored types.
+func (hook *bugsnagHook) ignoredError(err error) bool {
+	for _, ignored := range hook.ignoredErrors {
+		if errors.Is(err, ignored) {
+			return true
+		}
+	}
+	for _, typ := range hook.ignoredErrorTypes {
+		if errors.As(err, reflect.New(typ).Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredByFunc reports whether any of the functions set with
+// WithIgnoreErrorsFunc ignores the event reporting err and entry.
+func (hook *bugsnagHook) ignoredByFunc(entry *logrus.Entry, err error) bool {
+	for _, fn := range hook.ignoreFuncs {
+		if fn(entry, err) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignorable reports whether any of the matchers of the hook matches err.
+func (hook *bugsnagHook) ignorable(err error) bool {
+	for _, match := range hook.ignorableMatchers {
+		if match(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// grpcCanceled is the code of canceled gRPC calls, codes.Canceled.
+const grpcCanceled = 1
+
+// IsGRPCCanceled reports whether err is, or wraps, the error of a canceled
+// gRPC call, such as status.Error(codes.Canceled, "context canceled"). Clients
+// see their calls canceled when the context of the call is, and servers when
+// the client disconnects. Use it with WithIgnorableError:
+//
+//	hook, err := NewBugsnagHook(WithIgnorableError(IsGRPCCanceled))
+//
+// The status of the error is found through its GRPCStatus method, so that the
+// hook does not depend on grpc.
+func IsGRPCCanceled(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if code, ok := grpcCode(err); ok {
+			return code == grpcCanceled
+		}
+	}
+	return false
+}
+
+// grpcCode returns the code of the status returned by the GRPCStatus method of
+// err, if it has one.
+func grpcCode(err error) (uint64, bool) {
+	method := reflect.ValueOf(err).MethodByName("GRPCStatus")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return 0, false
+	}
+	status := method.Call(nil)[0]
+	if status.Kind() == reflect.Ptr && status.IsNil() {
+		return 0, false
+	}
+	code := status.MethodByName("Code")
+	if !code.IsValid() || code.Type().NumIn() != 0 || code.Type().NumOut() != 1 {
+		return 0, false
+	}
+	switch value := code.Call(nil)[0]; value.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint(), true
+	}
+	return 0, false
+}
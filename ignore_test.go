@@ -0,0 +1,108 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithIgnoreErrors(t *testing.T) {
+	log, hook, notifier := newFakeLogger(t, WithIgnoreErrors(io.EOF, net.ErrClosed))
+
+	log.WithError(io.EOF).Error("read failed")
+	log.WithError(fmt.Errorf("read body: %w", io.EOF)).Error("read failed")
+	log.WithError(&net.OpError{Op: "accept", Err: net.ErrClosed}).Error("accept failed")
+	log.WithError(io.ErrUnexpectedEOF).Error("read failed")
+
+	assert.Equal(t, []string{"unexpected EOF"}, messagesOf(notifier.sent()))
+	assert.Equal(t, int64(3), hook.Stats().Ignored)
+}
+
+func TestWithIgnoreErrorTypes(t *testing.T) {
+	log, _, notifier := newFakeLogger(t, WithIgnoreErrorTypes(
+		reflect.TypeOf((*url.Error)(nil)),
+		reflect.TypeOf((*interface{ Temporary() bool })(nil)).Elem(),
+		reflect.TypeOf(""),
+		nil,
+	))
+
+	log.WithError(&url.Error{Op: "Get", URL: "https://example.com", Err: io.EOF}).Error("get failed")
+	log.WithError(fmt.Errorf("resolve: %w", &net.DNSError{Err: "timeout", IsTemporary: true})).Error("resolve failed")
+	log.WithError(errors.New("other")).Error("failed")
+
+	assert.Equal(t, []string{"other"}, messagesOf(notifier.sent()))
+}
+
+func TestWithIgnoreErrorsFunc(t *testing.T) {
+	type maintenanceKey struct{}
+	inMaintenance := func(entry *logrus.Entry, err error) bool {
+		return entry.Context != nil && entry.Context.Value(maintenanceKey{}) == true
+	}
+	var dbErr *net.OpError
+	databaseErrors := func(entry *logrus.Entry, err error) bool {
+		return errors.As(err, &dbErr) && dbErr.Net == "postgres"
+	}
+	log, hook, notifier := newFakeLogger(t, WithIgnoreErrorsFunc(inMaintenance), WithIgnoreErrorsFunc(databaseErrors))
+
+	maintenance := context.WithValue(context.Background(), maintenanceKey{}, true)
+	log.WithContext(maintenance).WithError(errors.New("connection reset")).Error("query failed")
+	log.WithContext(maintenance).Error("no error field")
+	log.WithError(&net.OpError{Op: "dial", Net: "postgres", Err: errors.New("refused")}).Error("query failed")
+	log.WithError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("refused")}).Error("query failed")
+	log.Error("reported")
+
+	assert.Equal(t, []string{"dial tcp: refused", "reported"}, messagesOf(notifier.sent()))
+	assert.Equal(t, int64(3), hook.Stats().Ignored)
+}
+
+// fakeCode, grpcStatus and grpcError mimic codes.Code, status.Status and the
+// errors returned by status.Error.
+type fakeCode uint32
+
+type grpcStatus struct {
+	code    fakeCode
+	message string
+}
+
+func (s *grpcStatus) Code() fakeCode {
+	return s.code
+}
+
+type grpcError struct {
+	status *grpcStatus
+}
+
+func (e *grpcError) Error() string {
+	return fmt.Sprintf("rpc error: code = %d desc = %s", e.status.code, e.status.message)
+}
+
+func (e *grpcError) GRPCStatus() *grpcStatus {
+	return e.status
+}
+
+func TestWithIgnorableError(t *testing.T) {
+	const canceled, internal = 1, 13
+	log, hook, notifier := newFakeLogger(t, WithIgnorableError(IsGRPCCanceled))
+
+	log.WithError(&grpcError{&grpcStatus{canceled, "context canceled"}}).Error("call failed")
+	log.WithError(fmt.Errorf("get user: %w", &grpcError{&grpcStatus{canceled, "context canceled"}})).Error("call failed")
+	log.WithError(fmt.Errorf("get user: %w", context.Canceled)).Error("call failed")
+	log.WithError(&grpcError{&grpcStatus{internal, "boom"}}).Error("call failed")
+
+	assert.Equal(t, []string{"rpc error: code = 13 desc = boom"}, messagesOf(notifier.sent()))
+	assert.Equal(t, int64(3), hook.Stats().Ignored)
+
+	// Context cancellations are ignored without matchers.
+	log, _, notifier = newFakeLogger(t)
+	log.WithError(context.Canceled).Error("call failed")
+	log.WithError(&grpcError{&grpcStatus{canceled, "context canceled"}}).Error("call failed")
+	assert.Len(t, notifier.sent(), 1)
+}
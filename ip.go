@@ -0,0 +1,25 @@
+package logrus_bugsnag
+
+import "net"
+
+// normalizeIP returns the canonical string form of val if it is an IP
+// address or network: net.IPNet has no text form of its own and would be
+// serialized with its mask as a base64 blob. Other values are returned
+// unchanged.
+func normalizeIP(val interface{}) interface{} {
+	switch v := val.(type) {
+	case net.IP:
+		if v == nil {
+			return val
+		}
+		return v.String()
+	case net.IPNet:
+		return v.String()
+	case *net.IPNet:
+		if v == nil {
+			return val
+		}
+		return v.String()
+	}
+	return val
+}
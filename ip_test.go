@@ -0,0 +1,40 @@
+package logrus_bugsnag
+
+import (
+	"net"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPNormalization(t *testing.T) {
+	_, network, err := net.ParseCIDR("10.1.0.0/16")
+	assert.NoError(t, err)
+	fields := logrus.Fields{
+		"parsed":  net.ParseIP("192.0.2.1"),
+		"bytes":   net.IP([]byte{192, 0, 2, 2}),
+		"ipv6":    net.ParseIP("2001:db8::1"),
+		"network": network,
+		"value":   *network,
+		"raw":     []byte{1, 2},
+	}
+
+	log, _, c, teardown := newTestLogger(t)
+	defer teardown()
+	log.WithFields(fields).Error("ips")
+	metadata := receiveEvent(t, c).Metadata["metadata"]
+	assert.Equal(t, "192.0.2.1", metadata["parsed"])
+	assert.Equal(t, "192.0.2.2", metadata["bytes"])
+	assert.Equal(t, "2001:db8::1", metadata["ipv6"])
+	assert.Equal(t, "10.1.0.0/16", metadata["network"])
+	assert.Equal(t, "10.1.0.0/16", metadata["value"])
+	// Byte slices that are not IP addresses are unchanged.
+	assert.Equal(t, []interface{}{float64(1), float64(2)}, metadata["raw"])
+
+	log, _, c, teardown = newTestLogger(t, WithIPNormalization(false))
+	defer teardown()
+	log.WithFields(fields).Error("ips")
+	metadata = receiveEvent(t, c).Metadata["metadata"]
+	assert.Equal(t, []interface{}{float64(192), float64(0), float64(2), float64(2)}, metadata["bytes"])
+}
@@ -0,0 +1,137 @@
+package logrus_bugsnag
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+	"golang.org/x/time/rate"
+)
+
+// limiter suppresses events beyond a rate limit, and events identical to one
+// sent within a time window.
+type limiter struct {
+	// rate is nil if events are not rate limited.
+	rate *rate.Limiter
+	// window is zero if events are not deduplicated.
+	window time.Duration
+
+	mu   sync.Mutex
+	sent map[string]*sentEvent
+	// rateLimited counts the events suppressed by the rate limit since the
+	// last event sent.
+	rateLimited int
+	lastSweep   time.Time
+}
+
+// sentEvent records when an event was last sent and how many identical
+// events were suppressed since.
+type sentEvent struct {
+	at         time.Time
+	suppressed int
+}
+
+func (hook *bugsnagHook) ensureLimiter() *limiter {
+	if hook.limiter == nil {
+		hook.limiter = &limiter{sent: make(map[string]*sentEvent)}
+	}
+	return hook.limiter
+}
+
+// dedupKey identifies identical events: same error type, same message and
+// same top stack frame.
+func dedupKey(err *bugsnag_errors.Error) string {
+	key := err.TypeName() + "\x00" + err.Error()
+	if frames := err.StackFrames(); len(frames) > 0 {
+		key += fmt.Sprintf("\x00%s:%d", frames[0].File, frames[0].LineNumber)
+	}
+	return key
+}
+
+// allow reports whether an event with the given key may be sent. If it may,
+// it also returns the number of identical events and the number of rate
+// limited events suppressed since the last event sent, and the function to
+// call if the event is dropped afterwards, as by the circuit breaker: the
+// event then uses up neither the rate limit nor the deduplication window, and
+// the suppressed events are reported with the next event sent.
+func (l *limiter) allow(key string, now time.Time) (ok bool, duplicates, rateLimited int, release func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	previous := l.sent[key]
+	if l.window > 0 && previous != nil && now.Sub(previous.at) < l.window {
+		previous.suppressed++
+		return false, 0, 0, nil
+	}
+	var reservation *rate.Reservation
+	if l.rate != nil {
+		reservation = l.rate.ReserveN(now, 1)
+		if !reservation.OK() || reservation.DelayFrom(now) > 0 {
+			reservation.CancelAt(now)
+			l.rateLimited++
+			return false, 0, 0, nil
+		}
+	}
+
+	if previous != nil {
+		duplicates = previous.suppressed
+	}
+	rateLimited = l.rateLimited
+	l.rateLimited = 0
+	var current *sentEvent
+	if l.window > 0 {
+		current = &sentEvent{at: now}
+		l.sent[key] = current
+		l.sweep(now)
+	}
+	release = func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if reservation != nil {
+			reservation.CancelAt(now)
+		}
+		l.rateLimited += rateLimited
+		if current != nil && l.sent[key] == current {
+			if previous != nil {
+				l.sent[key] = previous
+			} else {
+				delete(l.sent, key)
+			}
+		}
+	}
+	return true, duplicates, rateLimited, release
+}
+
+// sweep forgets events sent before the current window, at most once per
+// window. Suppressed duplicates of forgotten events are not reported.
+func (l *limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+	for key, event := range l.sent {
+		if now.Sub(event.at) >= l.window {
+			delete(l.sent, key)
+		}
+	}
+}
+
+// reset forgets the events sent and the suppressed events.
+func (l *limiter) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sent = make(map[string]*sentEvent)
+	l.rateLimited = 0
+	l.lastSweep = time.Time{}
+}
+
+// ResetDeduplication forgets the events sent, so that the next event is sent
+// even if an identical event was sent within the deduplication window. The
+// counts of suppressed events are reset too.
+func (hook *bugsnagHook) ResetDeduplication() {
+	if hook.limiter != nil {
+		hook.limiter.reset()
+	}
+}
@@ -0,0 +1,115 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestDedupWindow(t *testing.T) {
+	log, _, notifier := newFakeLogger(t, WithDedupWindow(100*time.Millisecond))
+
+	// Both bursts are logged from the same line, for their events to share
+	// the top stack frame.
+	for burst := 0; burst < 2; burst++ {
+		if burst == 1 {
+			log.Error("another error")
+			assert.Equal(t, []string{"crash loop", "another error"}, messagesOf(notifier.sent()))
+			time.Sleep(100 * time.Millisecond)
+		}
+		for i := 0; i < 10; i++ {
+			log.Error("crash loop")
+		}
+	}
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "crash loop", calls[0].err.Error())
+	assert.Equal(t, 9, calls[0].metadata()["metadata"]["suppressed_duplicates"])
+}
+
+func TestRateLimit(t *testing.T) {
+	log, hook, notifier := newFakeLogger(t, WithRateLimit(rate.Every(time.Hour), 3))
+
+	for i := 0; i < 10; i++ {
+		log.Error(fmt.Sprintf("error %d", i))
+	}
+	assert.Equal(t, []string{"error 0", "error 1", "error 2"}, messagesOf(notifier.sent()))
+
+	hook.limiter.rate.SetLimit(rate.Inf)
+	log.Error("after the burst")
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "after the burst", calls[0].err.Error())
+	assert.Equal(t, 7, calls[0].metadata()["metadata"]["suppressed_rate_limited"])
+}
+
+func TestRateLimitAfterDrop(t *testing.T) {
+	cache := &fakeCache{}
+	log, hook, notifier := newFakeLogger(t, WithRateLimit(rate.Every(time.Hour), 1), WithDistributedDedup(cache, time.Minute))
+	other, _, _ := newFakeLogger(t, WithDistributedDedup(cache, time.Minute))
+
+	// The event sent elsewhere first is dropped by the distributed
+	// deduplication, and logged from the same line to share the top stack
+	// frame.
+	for _, l := range []*logrus.Logger{other, log} {
+		l.Error("failed to process order")
+	}
+	assert.Empty(t, notifier.sent())
+	assert.Equal(t, int64(1), hook.Stats().Dropped)
+
+	// It used up neither the rate limit nor the deduplication window.
+	log.Error("another failure")
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "another failure", calls[0].err.Error())
+	assert.NotContains(t, calls[0].metadata()["metadata"], "suppressed_rate_limited")
+}
+
+func TestLimiterRelease(t *testing.T) {
+	l := &limiter{rate: rate.NewLimiter(rate.Every(time.Hour), 1), window: time.Minute, sent: make(map[string]*sentEvent)}
+	now := time.Now()
+
+	ok, _, _, release := l.allow("key", now)
+	require.True(t, ok)
+	ok, _, _, _ = l.allow("other", now)
+	require.False(t, ok)
+	release()
+
+	// The released event frees its token and its key, and the rate limited
+	// event is reported with the next event sent.
+	ok, duplicates, rateLimited, _ := l.allow("key", now)
+	assert.True(t, ok)
+	assert.Equal(t, 0, duplicates)
+	assert.Equal(t, 1, rateLimited)
+}
+
+func TestDeduplication(t *testing.T) {
+	log, hook, notifier := newFakeLogger(t, WithDeduplication(10*time.Second))
+
+	for i := 0; i < 100; i++ {
+		log.WithError(errors.New("crash loop")).Error("oops")
+	}
+	assert.Len(t, notifier.sent(), 1)
+	assert.Equal(t, int64(99), hook.Stats().Dropped)
+
+	hook.ResetDeduplication()
+	for i := 0; i < 100; i++ {
+		log.WithError(errors.New("crash loop")).Error("oops")
+	}
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	assert.NotContains(t, calls[0].metadata()["metadata"], "suppressed_duplicates")
+
+	// Errors of different types are not identical, and are logged from the
+	// same line to share the top stack frame.
+	for _, err := range []error{errors.New("crash loop"), fmt.Errorf("%w", errors.New("crash loop"))} {
+		log.WithError(err).Error("oops")
+	}
+	assert.Len(t, notifier.sent(), 2)
+}
@@ -0,0 +1,27 @@
+package logrus_bugsnag
+
+// combinedError prefixes the message of an error with the message of the
+// entry reporting it.
+type combinedError struct {
+	error
+	message string
+}
+
+func (e combinedError) Error() string {
+	return e.message + ": " + e.error.Error()
+}
+
+func (e combinedError) Unwrap() error {
+	return e.error
+}
+
+// extractError returns the error found in the message of an entry without an
+// error field by the extractor set with WithMessageErrorExtractor, and the
+// rest of the message.
+func (hook *bugsnagHook) extractError(message string) (error, string, bool) {
+	if hook.messageErrorExtractor == nil || message == "" {
+		return nil, "", false
+	}
+	err, rest := hook.messageErrorExtractor(message)
+	return err, rest, err != nil
+}
@@ -0,0 +1,57 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// trailingErrno extracts the errno at the end of messages such as
+// "dial db: connection refused".
+func trailingErrno(msg string) (error, string) {
+	i := strings.LastIndex(msg, ": ")
+	if i < 0 || msg[i+2:] != syscall.ECONNREFUSED.Error() {
+		return nil, ""
+	}
+	return syscall.ECONNREFUSED, msg[:i]
+}
+
+func TestMessagesAndErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		err       error
+		message   string
+		want      string
+		wantClass string
+		wantRest  interface{}
+	}{
+		{"error and message", nil, syscall.ECONNREFUSED, "processing order 42 failed", "connection refused", "syscall.Errno", nil},
+		{"error and message combined", []Option{WithCombinedMessages(true)}, syscall.ECONNREFUSED, "processing order 42 failed", "processing order 42 failed: connection refused", "syscall.Errno", nil},
+		{"error only", []Option{WithCombinedMessages(true)}, syscall.ECONNREFUSED, "", "connection refused", "syscall.Errno", nil},
+		{"error as message", []Option{WithCombinedMessages(true)}, syscall.ECONNREFUSED, "connection refused", "connection refused", "syscall.Errno", nil},
+		{"message only", nil, nil, "dial db: connection refused", "dial db: connection refused", "*errors.errorString", nil},
+		{"message with extracted error", []Option{WithMessageErrorExtractor(trailingErrno)}, nil, "dial db: connection refused", "connection refused", "syscall.Errno", "dial db"},
+		{"message without extracted error", []Option{WithMessageErrorExtractor(trailingErrno)}, nil, "dial db: timeout", "dial db: timeout", "*errors.errorString", nil},
+		{"error and message with extractor", []Option{WithMessageErrorExtractor(trailingErrno)}, errors.New("bad gateway"), "dial db: connection refused", "bad gateway", "*errors.errorString", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, _, c, teardown := newTestLogger(t, tt.opts...)
+			defer teardown()
+
+			if tt.err != nil {
+				log.WithError(tt.err).Error(tt.message)
+			} else {
+				log.Error(tt.message)
+			}
+			event := receiveEvent(t, c)
+			assert.Equal(t, tt.want, event.Exceptions[0].Message)
+			assert.Equal(t, tt.wantClass, event.Exceptions[0].ErrorClass)
+			assert.Equal(t, tt.wantRest, event.Metadata["logrus"]["message"])
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package logrus_bugsnag
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+)
+
+const metadataTab = "metadata"
+
+// buildMetadata converts the entry's fields, other than "error", into Bugsnag
+// metadata. Fields are put in the "metadata" tab unless tab promotion is
+// enabled. The tabs of fields holding bugsnag.MetaData are merged instead.
+func (hook *bugsnagHook) buildMetadata(entry *logrus.Entry) bugsnag.MetaData {
+	metadata := bugsnag.MetaData{}
+	metadata[metadataTab] = make(map[string]interface{})
+	var provided []string
+	for key, val := range entry.Data {
+		if key == "error" || isReservedField(key) || hook.isFeatureFlagField(key) || hook.isTagField(key) || hook.isReleaseField(key) ||
+			hook.isPanicField(entry, key) || !hook.fieldAllowed(key) || (hook.dropNilFields && val == nil) {
+			continue
+		}
+		if _, ok := val.(bugsnag.MetaData); ok {
+			provided = append(provided, key)
+			continue
+		}
+		if hook.unwrapSQLNulls {
+			val = sqlValue(val)
+		}
+		if hook.normalizeIPs {
+			val = normalizeIP(val)
+		}
+		if hook.normalizeUUIDs {
+			val = normalizeUUID(val)
+		}
+		if hook.coercion != nil {
+			val = hook.coercion.coerce(key, val)
+		}
+		if hook.typedFields {
+			val = jsonValue(val)
+		}
+		if hook.promoteTabs && hook.promoteTab(metadata, key, val) {
+			continue
+		}
+		metadata[metadataTab][key] = hook.redactor.redact(key, val)
+	}
+	// Merge the fields in a stable order, should several hold metadata.
+	sort.Strings(provided)
+	for _, key := range provided {
+		hook.mergeProvided(metadata, entry.Data[key].(bugsnag.MetaData))
+	}
+	return metadata
+}
+
+// mergeProvided merges metadata provided by an entry field holding
+// bugsnag.MetaData, such as the metadata assembled by a middleware, into the
+// metadata of the event. Its tabs override the fields of the same tabs, except
+// in the "metadata" tab, where the fields of the entry are kept.
+func (hook *bugsnagHook) mergeProvided(metadata, provided bugsnag.MetaData) {
+	for tab, fields := range provided {
+		redacted := hook.redactor.redactMap(fields)
+		if tab != metadataTab {
+			mergeTab(metadata, tab, redacted)
+			continue
+		}
+		for key, val := range redacted {
+			if _, exists := metadata[metadataTab][key]; !exists {
+				metadata[metadataTab][key] = val
+			}
+		}
+	}
+}
+
+// fieldAllowed reports whether the entry field with the given name may be sent
+// to Bugsnag.
+func (hook *bugsnagHook) fieldAllowed(key string) bool {
+	if len(hook.allowedFields) == 0 {
+		return true
+	}
+	_, ok := hook.allowedFields[key]
+	return ok
+}
+
+// promoteTab adds a field holding a map to the metadata as its own tab named
+// after the field. It returns false if the field should be added to the
+// "metadata" tab instead.
+func (hook *bugsnagHook) promoteTab(metadata bugsnag.MetaData, key string, val interface{}) bool {
+	name := key
+	if hook.tabNameSanitizer != nil {
+		name = hook.tabNameSanitizer(key)
+	}
+	if name == metadataTab {
+		return false
+	}
+	if tab, ok := hook.redactor.redact(key, val).(map[string]interface{}); ok {
+		mergeTab(metadata, name, tab)
+		return true
+	}
+	return false
+}
+
+// sanitizeTabName is the default sanitizer of the names of promoted tabs. It
+// trims whitespace, replaces the characters other than letters, digits,
+// spaces, '.', '-' and '_' with underscores, and returns "metadata" for empty
+// names.
+func sanitizeTabName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return metadataTab
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// mergeTab adds fields to the given metadata tab, creating it if needed.
+func mergeTab(metadata bugsnag.MetaData, tab string, fields map[string]interface{}) {
+	if metadata[tab] == nil {
+		metadata[tab] = fields
+		return
+	}
+	for key, val := range fields {
+		metadata[tab][key] = val
+	}
+}
+
+// copyMap returns a shallow copy of m.
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for key, val := range m {
+		c[key] = val
+	}
+	return c
+}
+
+// setStaticTab sets a tab added to every event, or removes it if fields is
+// empty.
+func (hook *bugsnagHook) setStaticTab(tab string, fields map[string]interface{}) {
+	if len(fields) == 0 {
+		delete(hook.staticTabs, tab)
+		return
+	}
+	if hook.staticTabs == nil {
+		hook.staticTabs = bugsnag.MetaData{}
+	}
+	hook.staticTabs[tab] = fields
+}
@@ -0,0 +1,188 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTabPromotion(t *testing.T) {
+	fields := logrus.Fields{
+		"db":      logrus.Fields{"query": "SELECT 1", "rows": 3},
+		"request": map[string]interface{}{"path": "/orders", "token": "abc"},
+		"extra": bugsnag.MetaData{
+			"user":    {"id": "42"},
+			"request": {"method": "POST"},
+		},
+		"animal": "walrus",
+	}
+
+	log, _, c, teardown := newTestLogger(t, WithTabPromotion(true))
+	defer teardown()
+
+	log.WithFields(fields).Error("tabs")
+	event := receiveEvent(t, c)
+	assert.Equal(t, bugsnag.MetaData{
+		"metadata": {"animal": "walrus"},
+		"db":       {"query": "SELECT 1", "rows": float64(3)},
+		"request":  {"path": "/orders", "token": "[REDACTED]", "method": "POST"},
+		"user":     {"id": "42"},
+	}, event.Metadata)
+
+	// Without the option, maps stay nested in the "metadata" tab.
+	log, _, c, teardown = newTestLogger(t)
+	defer teardown()
+
+	log.WithFields(fields).Error("no tabs")
+	event = receiveEvent(t, c)
+	assert.ElementsMatch(t, []string{"metadata", "user", "request"}, tabNames(event.Metadata))
+	assert.Equal(t, map[string]interface{}{"query": "SELECT 1", "rows": float64(3)}, event.Metadata["metadata"]["db"])
+}
+
+func TestWithSectionNameSanitizer(t *testing.T) {
+	fields := logrus.Fields{
+		" db ":       logrus.Fields{"query": "SELECT 1"},
+		"http/req":   logrus.Fields{"path": "/orders"},
+		"   ":        logrus.Fields{"blank": true},
+		"user-agent": logrus.Fields{"name": "curl"},
+	}
+
+	log, _, c, teardown := newTestLogger(t, WithTabPromotion(true))
+	defer teardown()
+
+	log.WithFields(fields).Error("tabs")
+	event := receiveEvent(t, c)
+	assert.ElementsMatch(t, []string{"metadata", "db", "http_req", "user-agent"}, tabNames(event.Metadata))
+	assert.Equal(t, map[string]interface{}{"blank": true}, event.Metadata["metadata"]["   "])
+
+	log, _, c, teardown = newTestLogger(t, WithTabPromotion(true), WithSectionNameSanitizer(strings.ToUpper))
+	defer teardown()
+
+	log.WithFields(fields).Error("tabs")
+	event = receiveEvent(t, c)
+	assert.ElementsMatch(t, []string{"metadata", " DB ", "HTTP/REQ", "   ", "USER-AGENT"}, tabNames(event.Metadata))
+}
+
+func tabNames(metadata bugsnag.MetaData) []string {
+	var names []string
+	for tab := range metadata {
+		names = append(names, tab)
+	}
+	return names
+}
+
+func TestProvidedMetadata(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t)
+	defer teardown()
+
+	provided := bugsnag.MetaData{
+		"metadata": {"animal": "penguin", "route": "/orders/{id}"},
+		"request": {
+			"method":  "POST",
+			"headers": map[string]interface{}{"Accept": "application/json", "X-Api-Token": "abc"},
+		},
+		"panic": {"goroutine": float64(7)},
+	}
+	log.WithFields(logrus.Fields{
+		"middleware": provided,
+		"animal":     "walrus",
+		"request":    map[string]interface{}{"method": "GET"},
+	}).Error("provided")
+
+	event := receiveEvent(t, c)
+	assert.Equal(t, bugsnag.MetaData{
+		// The fields of the entry win in the "metadata" tab.
+		"metadata": {
+			"animal":  "walrus",
+			"route":   "/orders/{id}",
+			"request": map[string]interface{}{"method": "GET"},
+		},
+		// The provided tabs are merged with their nested maps.
+		"request": {
+			"method":  "POST",
+			"headers": map[string]interface{}{"Accept": "application/json", "X-Api-Token": "[REDACTED]"},
+		},
+		"panic": {"goroutine": float64(7)},
+	}, event.Metadata)
+	// The provided metadata is left untouched.
+	assert.Equal(t, "abc", provided["request"]["headers"].(map[string]interface{})["X-Api-Token"])
+
+	// The provided tabs win over the tabs promoted from the entry's fields.
+	log, _, c, teardown = newTestLogger(t, WithTabPromotion(true))
+	defer teardown()
+	log.WithFields(logrus.Fields{
+		"middleware": provided,
+		"request":    map[string]interface{}{"method": "GET", "path": "/orders/42"},
+	}).Error("promoted")
+	event = receiveEvent(t, c)
+	assert.Equal(t, "POST", event.Metadata["request"]["method"])
+	assert.Equal(t, "/orders/42", event.Metadata["request"]["path"])
+}
+
+func TestAllowedFields(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithAllowedFields("animal", "size"))
+	defer teardown()
+
+	log.WithFields(logrus.Fields{
+		"animal":  "walrus",
+		"size":    9009,
+		"omg":     true,
+		"user_id": 42,
+		"path":    "/orders",
+	}).Error("allowed")
+	event := receiveEvent(t, c)
+	assert.Equal(t, map[string]interface{}{"animal": "walrus", "size": float64(9009)}, event.Metadata["metadata"])
+}
+
+func TestIncludeLogMessage(t *testing.T) {
+	log, _, notifier := newFakeLogger(t)
+	log.WithError(errors.New("connection reset")).Error("failed to process order")
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "connection reset", calls[0].err.Error())
+	assert.NotContains(t, calls[0].metadata()["metadata"], "log_message")
+
+	log, _, notifier = newFakeLogger(t, WithIncludeLogMessage("log_message"))
+	log.WithError(errors.New("connection reset")).Error("failed to process order")
+	calls = notifier.sent()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "connection reset", calls[0].err.Error())
+	assert.Equal(t, "failed to process order", calls[0].metadata()["metadata"]["log_message"])
+}
+
+func TestEntryTimestampField(t *testing.T) {
+	log, _, notifier := newFakeLogger(t)
+	log.Error("failed to process order")
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	assert.NotContains(t, calls[0].metadata()["metadata"], "logged_at")
+
+	log, _, notifier = newFakeLogger(t, WithEntryTimestampField("logged_at"))
+	logged := time.Date(2019, time.March, 14, 15, 9, 26, 535897000, time.FixedZone("NZDT", 13*60*60))
+	log.WithTime(logged).Error("failed to process order")
+	calls = notifier.sent()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "2019-03-14T02:09:26.535897Z", calls[0].metadata()["metadata"]["logged_at"])
+}
+
+func TestNilFieldDropping(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithNilFieldDropping(true))
+	defer teardown()
+
+	log.WithField("err", error(nil)).WithField("animal", "walrus").Error("dropped")
+	event := receiveEvent(t, c)
+	assert.Equal(t, map[string]interface{}{"animal": "walrus"}, event.Metadata["metadata"])
+
+	log, _, c, teardown = newTestLogger(t)
+	defer teardown()
+	log.WithField("err", error(nil)).Error("kept")
+	event = receiveEvent(t, c)
+	// bugsnag sends nil values as "<nil>".
+	assert.Equal(t, map[string]interface{}{"err": "<nil>"}, event.Metadata["metadata"])
+}
@@ -0,0 +1,43 @@
+package logrus_bugsnag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// stacklessError makes bugsnag report an error without capturing a stack
+// trace.
+type stacklessError struct {
+	error
+}
+
+func (stacklessError) Callers() []uintptr {
+	return nil
+}
+
+// finalizeMinimal returns the event reporting notifyErr with only its class,
+// its message and the precomputed minimal metadata.
+func (hook *bugsnagHook) finalizeMinimal(entry *logrus.Entry, notifyErr error) *FinalizedEvent {
+	errorClass := fmt.Sprintf("%T", notifyErr)
+	message := notifyErr.Error()
+	hash := sha256.Sum256([]byte(errorClass + "\x00" + message))
+
+	rawData := []interface{}{
+		hook.minimalMetadata,
+		bugsnag.ErrorClass{Name: hook.errorClass(notifyErr, errorClass)},
+		groupingHash(hex.EncodeToString(hash[:16])),
+	}
+	if entry.Level == logrus.WarnLevel {
+		rawData = append(rawData, bugsnag.SeverityWarning)
+	}
+	if config, ok := hook.releaseOverride(entry); ok {
+		rawData = append(rawData, config)
+	}
+	_, tabSizes := measure(hook.minimalMetadata)
+	return &FinalizedEvent{Error: bugsnag_errors.New(stacklessError{notifyErr}, 0), RawData: rawData, tabSizes: tabSizes}
+}
@@ -0,0 +1,106 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reconcileError struct {
+	msg string
+}
+
+func (e *reconcileError) Error() string {
+	return e.msg
+}
+
+func isReconcile(entry *logrus.Entry) bool {
+	_, ok := entry.Data["error"].(*reconcileError)
+	return ok
+}
+
+func TestMinimalMode(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithMinimalMode(isReconcile))
+	defer teardown()
+
+	log.WithFields(logrus.Fields{
+		"error":  &reconcileError{"stale object"},
+		"animal": "walrus",
+	}).Error("reconcile")
+	minimal := receiveEvent(t, c)
+	assert.Equal(t, "*logrus_bugsnag.reconcileError", minimal.Exceptions[0].ErrorClass)
+	assert.Equal(t, "stale object", minimal.Exceptions[0].Message)
+	assert.Empty(t, minimal.Exceptions[0].Stacktrace)
+	assert.Equal(t, bugsnag.MetaData{"metadata": {"minimal_mode": true}}, minimal.Metadata)
+	assert.NotEmpty(t, minimal.GroupingHash)
+
+	// The same error groups together, a different one does not.
+	log.WithError(&reconcileError{"stale object"}).Error("reconcile")
+	assert.Equal(t, minimal.GroupingHash, receiveEvent(t, c).GroupingHash)
+
+	log.WithFields(logrus.Fields{
+		"error":  errors.New("not minimal"),
+		"animal": "walrus",
+	}).Error("full")
+	full := receiveEvent(t, c)
+	assert.NotEmpty(t, full.Exceptions[0].Stacktrace)
+	assert.Equal(t, "walrus", full.Metadata["metadata"]["animal"])
+	assert.Empty(t, full.GroupingHash)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// newBenchmarkLogger returns a logger whose hook delivers events to a
+// transport that accepts them without any network I/O.
+func newBenchmarkLogger(b *testing.B, opts ...Option) *logrus.Logger {
+	notifier := bugsnag.New(bugsnag.Configuration{
+		APIKey:      "12345678901234567890123456789012",
+		Endpoints:   bugsnag.Endpoints{Notify: "http://bugsnag.invalid", Sessions: "http://bugsnag.invalid"},
+		Synchronous: true,
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}, nil
+		}),
+	})
+	hook, err := NewBugsnagHookWithNotifier(notifier, opts...)
+	require.NoError(b, err, "failed to create hook")
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+	return log
+}
+
+func benchmarkReconcileErrors(b *testing.B, log *logrus.Logger) {
+	entry := log.WithFields(logrus.Fields{
+		"error":     &reconcileError{"stale object"},
+		"object_id": 42,
+		"kind":      "deployment",
+		"attempt":   3,
+	})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		entry.Error("reconcile")
+	}
+}
+
+func BenchmarkFullMode(b *testing.B) {
+	benchmarkReconcileErrors(b, newBenchmarkLogger(b))
+}
+
+func BenchmarkMinimalMode(b *testing.B) {
+	benchmarkReconcileErrors(b, newBenchmarkLogger(b, WithMinimalMode(isReconcile)))
+}
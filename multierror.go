@@ -0,0 +1,91 @@
+package logrus_bugsnag
+
+import (
+	"fmt"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// MultiErrorMode selects how the hook reports multi-errors, which join
+// several errors and implement Unwrap() []error, as the errors returned by
+// errors.Join do.
+type MultiErrorMode int
+
+const (
+	// MultiErrorSingle reports a multi-error like any other error, with the
+	// messages of its errors concatenated. It is the default mode.
+	MultiErrorSingle MultiErrorMode = iota
+	// MultiErrorSeparate reports each error of a multi-error as its own
+	// event, with the fields of the entry.
+	MultiErrorSeparate
+	// MultiErrorGrouped reports a multi-error as one event with an "errors"
+	// tab describing each of its errors.
+	MultiErrorGrouped
+)
+
+// defaultMaxMultiErrors is the default maximum number of errors of a
+// multi-error reported separately or described in the "errors" tab.
+const defaultMaxMultiErrors = 5
+
+// multiErrorsTab describes the errors of a multi-error in grouped mode.
+const multiErrorsTab = "errors"
+
+// multiErrors returns up to the maximum number of errors joined by err, or
+// nil if err is not a multi-error.
+func (hook *bugsnagHook) multiErrors(err error) []error {
+	multi, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil
+	}
+	errs := multi.Unwrap()
+	if len(errs) > hook.maxMultiErrors {
+		errs = errs[:hook.maxMultiErrors]
+	}
+	return errs
+}
+
+// splitMultiError returns an entry for each error of the multi-error in the
+// "error" field of entry, or nil if the hook does not report them separately.
+func (hook *bugsnagHook) splitMultiError(entry *logrus.Entry) []*logrus.Entry {
+	if hook.multiErrorMode != MultiErrorSeparate {
+		return nil
+	}
+	err, _ := entry.Data["error"].(error)
+	errs := hook.multiErrors(err)
+	if errs == nil {
+		return nil
+	}
+	entries := make([]*logrus.Entry, len(errs))
+	for i, err := range errs {
+		split := *entry
+		split.Data = make(logrus.Fields, len(entry.Data))
+		for key, val := range entry.Data {
+			split.Data[key] = val
+		}
+		split.Data["error"] = err
+		entries[i] = &split
+	}
+	return entries
+}
+
+// addMultiErrors describes the errors of err, if it is a multi-error, in the
+// "errors" tab: their class, their message and their stack trace if they
+// carry one.
+func (hook *bugsnagHook) addMultiErrors(metadata bugsnag.MetaData, err error) {
+	for i, err := range hook.multiErrors(err) {
+		description := map[string]interface{}{
+			"class":   hook.errorClass(err, fmt.Sprintf("%T", err)),
+			"message": err.Error(),
+		}
+		if _, ok := err.(interface{ Callers() []uintptr }); ok {
+			var stack []string
+			for _, frame := range bugsnag_errors.New(err, 0).StackFrames() {
+				stack = append(stack, fmt.Sprintf("%s:%d %s", frame.File, frame.LineNumber, frame.Name))
+			}
+			description["stacktrace"] = stack
+		}
+		metadata.Add(multiErrorsTab, fmt.Sprint(i+1), description)
+	}
+}
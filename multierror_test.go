@@ -0,0 +1,64 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"testing"
+
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stackError returns an error carrying a stack trace captured in this
+// function.
+func stackError(message string) error {
+	return bugsnag_errors.New(errors.New(message), 0)
+}
+
+func TestMultiErrorsSeparate(t *testing.T) {
+	log, hook, c, teardown := newTestLogger(t, WithMultiErrors(MultiErrorSeparate, 0))
+	defer teardown()
+
+	joined := errors.Join(errors.New("row 1 is invalid"), &validationError{}, stackError("row 3 is invalid"))
+	log.WithError(joined).WithField("batch", "42").Error("import failed")
+
+	events := c.Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, []string{"row 1 is invalid", "invalid order", "row 3 is invalid"}, messages(events))
+	assert.Equal(t, "*logrus_bugsnag.validationError", events[1].Exceptions[0].ErrorClass)
+	for _, event := range events {
+		assert.Equal(t, map[string]interface{}{"batch": "42"}, event.Metadata["metadata"])
+	}
+	assert.Equal(t, "TestMultiErrorsSeparate", events[0].Exceptions[0].Stacktrace[0].Method)
+	assert.Equal(t, "stackError", events[2].Exceptions[0].Stacktrace[0].Method)
+	assert.Equal(t, int64(3), hook.Stats().Sent)
+
+	// Errors beyond the maximum are not reported.
+	log, _, c, teardown = newTestLogger(t, WithMultiErrors(MultiErrorSeparate, 2))
+	defer teardown()
+	log.WithError(joined).Error("import failed")
+	assert.Equal(t, []string{"row 1 is invalid", "invalid order"}, messages(c.Events()))
+}
+
+func TestMultiErrorsGrouped(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithMultiErrors(MultiErrorGrouped, 0))
+	defer teardown()
+
+	joined := errors.Join(errors.New("row 1 is invalid"), &validationError{}, stackError("row 3 is invalid"))
+	log.WithError(joined).Error("import failed")
+
+	event := receiveEvent(t, c)
+	assert.Equal(t, joined.Error(), event.Exceptions[0].Message)
+	described := event.Metadata["errors"]
+	require.Len(t, described, 3)
+	assert.Equal(t, map[string]interface{}{"class": "*errors.errorString", "message": "row 1 is invalid"}, described["1"])
+	assert.Equal(t, map[string]interface{}{"class": "*logrus_bugsnag.validationError", "message": "invalid order"}, described["2"])
+	third := described["3"].(map[string]interface{})
+	assert.Equal(t, "row 3 is invalid", third["message"])
+	require.NotEmpty(t, third["stacktrace"])
+	assert.Contains(t, third["stacktrace"].([]interface{})[0], "stackError")
+
+	// Other errors are reported as usual.
+	log.WithError(errors.New("single")).Error("import failed")
+	assert.NotContains(t, receiveEvent(t, c).Metadata, "errors")
+}
@@ -0,0 +1,22 @@
+package logrus_bugsnag
+
+import (
+	bugsnag "github.com/bugsnag/bugsnag-go"
+)
+
+// Notifier sends errors to Bugsnag. *bugsnag.Notifier implements it, and
+// tests can implement it to check what the hook sends without a Bugsnag
+// server.
+type Notifier interface {
+	Notify(err error, rawData ...interface{}) error
+}
+
+// synchronous sends with a bugsnag notifier synchronously, whatever its
+// configuration.
+type synchronous struct {
+	*bugsnag.Notifier
+}
+
+func (n synchronous) Notify(err error, rawData ...interface{}) error {
+	return n.NotifySync(err, true, rawData...)
+}
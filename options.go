@@ -0,0 +1,897 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// Option configures optional behaviour of the hook created by NewBugsnagHook.
+type Option func(*bugsnagHook)
+
+// WithRedactedFields adds field names to the list of fields whose values are
+// replaced with "[REDACTED]" before being sent to Bugsnag. Matching is
+// case-insensitive and a field matches if its name contains any of the given
+// strings. The default list (password, secret, token, authorization) is always
+// applied. bugsnag applies its own ParamsFilters to the metadata afterwards,
+// replacing the values of the fields they match with "[FILTERED]", so fields
+// matched by both are sent as "[FILTERED]".
+func WithRedactedFields(fields ...string) Option {
+	return func(hook *bugsnagHook) {
+		hook.redactor.addFields(fields...)
+	}
+}
+
+// WithRedactFunc sets a custom redaction rule. fn is called for every metadata
+// field, including fields of nested maps, that is not already matched by the
+// redacted field list. If it returns true, the field value is replaced by the
+// returned value.
+func WithRedactFunc(fn func(key string, value interface{}) (interface{}, bool)) Option {
+	return func(hook *bugsnagHook) {
+		hook.redactor.fn = fn
+	}
+}
+
+// WithMirrorConfig sends a copy of every event to a second Bugsnag
+// configuration, for example a staging project. cfg is applied on top of the
+// global bugsnag configuration at the time the hook is created. Mirrored
+// events are sent asynchronously and their failure is ignored.
+func WithMirrorConfig(cfg bugsnag.Configuration) Option {
+	return func(hook *bugsnagHook) {
+		hook.mirror = bugsnag.New(cfg)
+	}
+}
+
+// WithRunbookResolver attaches a link to a runbook to events, as "runbook_url"
+// in the "resolution" tab. The resolver is given the error class the event is
+// reported with and the entry's "component" field. It can be replaced later
+// with SetRunbookResolver. It is only used if the WithRunbookURL function, if
+// any, returns "".
+func WithRunbookResolver(resolver RunbookResolver) Option {
+	return func(hook *bugsnagHook) {
+		hook.runbookResolver = resolver
+	}
+}
+
+// WithErrorChain adds the messages of the errors wrapped by the reported
+// error, as found with errors.Unwrap, to the "error_chain" field of the
+// "metadata" tab. It is disabled by default. See WithErrorChainTypes to report
+// only the errors of given types, in a tab of their own.
+func WithErrorChain(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.errorChain = enabled
+	}
+}
+
+// WithTabPromotion reports entry fields holding a map[string]interface{} or
+// logrus.Fields as their own Bugsnag tab named after the field, instead of a
+// nested value in the "metadata" tab. It is disabled by default. The tabs of
+// fields holding a bugsnag.MetaData are always merged into the event.
+func WithTabPromotion(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.promoteTabs = enabled
+	}
+}
+
+// WithIPNormalization sends entry fields holding a net.IP or a net.IPNet as
+// their canonical string form, such as "192.0.2.1" or "10.1.0.0/16", rather
+// than as arrays of bytes. Other byte slices are unchanged. It is enabled by
+// default.
+func WithIPNormalization(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.normalizeIPs = enabled
+	}
+}
+
+// WithUUIDNormalization sends entry fields holding UUIDs, such as
+// github.com/google/uuid UUIDs or plain [16]byte values, as their canonical
+// hyphenated string rather than as arrays of bytes. It is disabled by
+// default.
+func WithUUIDNormalization(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.normalizeUUIDs = enabled
+	}
+}
+
+// WithAllowedFields restricts the entry fields sent to Bugsnag to the given
+// names; any other field is dropped from the metadata. By default all fields
+// are sent.
+func WithAllowedFields(fields ...string) Option {
+	return func(hook *bugsnagHook) {
+		if hook.allowedFields == nil {
+			hook.allowedFields = make(map[string]struct{}, len(fields))
+		}
+		for _, field := range fields {
+			hook.allowedFields[field] = struct{}{}
+		}
+	}
+}
+
+// WithMinimalMode reports entries for which matcher returns true at the lowest
+// possible cost, for very frequent errors. Such events skip metadata building
+// and carry no stack trace: only the error class and message are sent, along
+// with a "metadata" tab marking the event as minimal. They are grouped in
+// Bugsnag by error class and message.
+func WithMinimalMode(matcher func(*logrus.Entry) bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.minimalMatcher = matcher
+		hook.minimalMetadata = bugsnag.MetaData{
+			metadataTab: {"minimal_mode": true},
+		}
+	}
+}
+
+// WithSkipPackages skips stack frames belonging to packages whose path
+// contains any of the given strings at the top of stack traces, the same way
+// frames of logrus and of this package are. Use it to hide the frames of a
+// package wrapping logrus.
+func WithSkipPackages(packages ...string) Option {
+	return func(hook *bugsnagHook) {
+		hook.skipPackages = append(hook.skipPackages, packages...)
+	}
+}
+
+// WithProcessMetadata adds a "process" tab to events, holding the process ID,
+// the hostname, the binary name and the main package path of the binary.
+// These are read once when the hook is created.
+func WithProcessMetadata(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		if enabled {
+			hook.setStaticTab(processTab, processMetadata())
+		} else {
+			hook.setStaticTab(processTab, nil)
+		}
+	}
+}
+
+// WithStaticMetadata adds a tab holding the given fields to every event. The
+// fields are deeply copied, and should be computed once when the hook is created,
+// such as build or environment information. A tab with no fields is not
+// added.
+func WithStaticMetadata(tab string, fields map[string]interface{}) Option {
+	return func(hook *bugsnagHook) {
+		hook.setStaticTab(tab, deepCopyFields(fields))
+	}
+}
+
+// WithDeferredConfigCheck allows NewBugsnagHook to create the hook before
+// bugsnag.Configure is called. Until bugsnag is configured, entries are
+// dropped, or Fire returns ErrBugsnagUnconfigured if reportUnconfigured is
+// true. The hook takes its configuration snapshot on the first entry logged
+// after bugsnag is configured.
+func WithDeferredConfigCheck(reportUnconfigured bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.deferConfigCheck = true
+		hook.reportUnconfigured = reportUnconfigured
+	}
+}
+
+// WithRunbookURL attaches a link to a runbook to events, as "runbook_url" in
+// the "resolution" tab. fn is given the reported error and returns the URL of
+// its runbook, or "" if there is none.
+func WithRunbookURL(fn func(error) string) Option {
+	return func(hook *bugsnagHook) {
+		hook.runbookURL = fn
+	}
+}
+
+// WithName names the hook, to tell which hook failed in the errors returned
+// by FlushAll and CloseAll.
+func WithName(name string) Option {
+	return func(hook *bugsnagHook) {
+		hook.name = name
+	}
+}
+
+// WithRegistry adds the hook to the given registry instead of
+// DefaultRegistry. A nil registry keeps the hook out of any registry.
+func WithRegistry(registry *Registry) Option {
+	return func(hook *bugsnagHook) {
+		hook.registry = registry
+	}
+}
+
+// WithRateLimit limits the events sent to Bugsnag to r per second, with bursts
+// of up to burst events. The number of events suppressed by the limit is
+// reported as "suppressed_rate_limited" in the metadata of the next event
+// sent.
+func WithRateLimit(r rate.Limit, burst int) Option {
+	return func(hook *bugsnagHook) {
+		hook.ensureLimiter().rate = rate.NewLimiter(r, burst)
+	}
+}
+
+// WithDedupWindow suppresses events identical to one sent less than d ago.
+// Events are identical if they have the same error type, the same message and
+// the same top stack frame. The number of suppressed events is reported as
+// "suppressed_duplicates" in the metadata of the next identical event sent.
+func WithDedupWindow(d time.Duration) Option {
+	return func(hook *bugsnagHook) {
+		hook.ensureLimiter().window = d
+	}
+}
+
+// WithDeduplication suppresses events identical to one sent less than window
+// ago, like WithDedupWindow. Events are identical if they have the same error
+// type, the same message and the same top stack frame.
+func WithDeduplication(window time.Duration) Option {
+	return WithDedupWindow(window)
+}
+
+// WithOnSendError calls fn with the error and the entry whenever an event
+// fails to be delivered to Bugsnag, for example to count failures in a metrics
+// system. fn is called from a background goroutine for asynchronous
+// deliveries, so it must be safe for concurrent use.
+func WithOnSendError(fn func(error, *logrus.Entry)) Option {
+	return func(hook *bugsnagHook) {
+		hook.onSendError = fn
+	}
+}
+
+// WithDisabled creates the hook disabled, so that it sends nothing to Bugsnag
+// until SetEnabled(true) is called. This allows the hook to be installed in
+// environments that must not report errors, such as local development.
+func WithDisabled() Option {
+	return func(hook *bugsnagHook) {
+		hook.disabled = true
+	}
+}
+
+// WithInitializationSuppression ignores the entries fired during the first d
+// after the hook is created, such as the spurious errors of a service that is
+// starting up.
+func WithInitializationSuppression(d time.Duration) Option {
+	return func(hook *bugsnagHook) {
+		hook.initSuppression = d
+	}
+}
+
+// WithCompression compresses the payloads sent to Bugsnag with gzip, and sets
+// their Content-Encoding accordingly. Enable it only if the collector accepts
+// compressed payloads. It is disabled by default.
+func WithCompression(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.compress = enabled
+	}
+}
+
+// WithBatching sends the events in batches of up to size events per request,
+// for collectors that accept payloads with several events. A batch is sent
+// when it is full, or interval after its first event, or by Flush, and before
+// its payload would exceed the 1MB Bugsnag accepts.
+//
+// The events are delivered in the background, as with an asynchronous
+// configuration, so Fire returns without waiting for their batch. The outcome
+// of each event is that of its batch, and failures are retried and reported
+// like those of other deliveries. It is disabled by default.
+func WithBatching(size int, interval time.Duration) Option {
+	return func(hook *bugsnagHook) {
+		if size <= 1 {
+			hook.batch = nil
+			return
+		}
+		hook.batch = &batcher{size: size, interval: interval, maxBytes: maxBatchBytes}
+	}
+}
+
+// WithRetry retries the deliveries that fail, making up to maxAttempts
+// attempts in total. The delay before each retry doubles from baseDelay, up to
+// a minute unless baseDelay is longer, with random jitter. Retries run in the
+// background, so the events of a synchronous configuration that failed once
+// are no longer reported as errors by Fire: use WithOnSendError or Stats to
+// watch for events that exhausted their retries.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(hook *bugsnagHook) {
+		hook.retry.maxAttempts = maxAttempts
+		hook.retry.baseDelay = baseDelay
+	}
+}
+
+// WithCircuitBreaker stops delivering events after failures consecutive
+// delivery failures within window, so that logging does not pay for the
+// timeouts of an unavailable endpoint. Events are dropped for coolDown, then
+// a single event is delivered to probe the endpoint: delivery resumes if it
+// succeeds, and stops for another coolDown otherwise. The state of the
+// breaker is reported by Stats.
+func WithCircuitBreaker(failures int, window, coolDown time.Duration) Option {
+	return func(hook *bugsnagHook) {
+		if failures <= 0 {
+			hook.breaker = nil
+			return
+		}
+		hook.breaker = &circuitBreaker{threshold: failures, window: window, coolDown: coolDown}
+	}
+}
+
+// WithNotifier sends events with n instead of a notifier configured like
+// bugsnag. The hook then needs no bugsnag configuration, and n is called
+// synchronously from Fire. Options configuring the delivery of a bugsnag
+// notifier, such as WithCompression or WithBatching, have no effect.
+func WithNotifier(n Notifier) Option {
+	return func(hook *bugsnagHook) {
+		hook.sender = n
+		if n != nil {
+			hook.ownNotifier = true
+		}
+	}
+}
+
+// WithIncludeLogMessage adds the message of the entry to the "metadata" tab
+// under fieldName. The message is otherwise lost when the entry has an
+// "error" field, whose message is sent instead. It is disabled by default.
+func WithIncludeLogMessage(fieldName string) Option {
+	return func(hook *bugsnagHook) {
+		hook.logMessageField = fieldName
+	}
+}
+
+// WithTitleCaseMessages capitalizes the first letter of each word of the
+// message sent as the title of Bugsnag events, as Go error messages are
+// usually lowercase. The original message is kept as "original_message" in a
+// "logrus" tab. It is disabled by default.
+func WithTitleCaseMessages(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.titleCase = enabled
+	}
+}
+
+// WithBreadcrumbs adds the entries recorded by r, oldest first, to a
+// "breadcrumbs" tab of the events. Their fields are redacted like the fields
+// of the entries reported.
+func WithBreadcrumbs(r *BreadcrumbRecorder) Option {
+	return func(hook *bugsnagHook) {
+		hook.breadcrumbs = r
+	}
+}
+
+// WithFingerprintFields groups the events in Bugsnag by the values of the
+// given entry fields, whatever their message. The grouping hash of an event
+// is computed from the values of the fields it has, in the given order.
+// Events with none of the fields are grouped as usual.
+func WithFingerprintFields(fields ...string) Option {
+	return func(hook *bugsnagHook) {
+		hook.fingerprintFields = fields
+	}
+}
+
+// WithQuarantine keeps up to capacity events whose delivery failed, after any
+// retries, instead of losing them. The oldest are evicted when the quarantine
+// is full. Quarantined events can be inspected with Quarantined and delivered
+// again with RetryQuarantined.
+func WithQuarantine(capacity int) Option {
+	return func(hook *bugsnagHook) {
+		if capacity <= 0 {
+			hook.quarantine = nil
+			return
+		}
+		hook.quarantine = &quarantine{capacity: capacity}
+	}
+}
+
+// WithForceField makes the hook report the entries below its levels that
+// have the ForceField field set to true. The hook then receives the entries
+// of all levels from the logger.
+func WithForceField(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.forceField = enabled
+	}
+}
+
+// WithMetadataPlugins lets the plugins add to or modify the metadata of each
+// event, in the given order. RuntimeStatsPlugin and BuildInfoPlugin are
+// provided.
+func WithMetadataPlugins(plugins ...MetadataPlugin) Option {
+	return func(hook *bugsnagHook) {
+		hook.plugins = append(hook.plugins, plugins...)
+	}
+}
+
+// WithIntegerCoercion sends the integer entry fields whose magnitude exceeds
+// 2^53 as strings, as they would lose precision once decoded from JSON as
+// floating point numbers. The integer fields whose keys match one of
+// keyPatterns, such as "*_id", are always sent as strings. Patterns use the
+// syntax of path.Match. Other numbers stay numeric.
+func WithIntegerCoercion(keyPatterns ...string) Option {
+	return func(hook *bugsnagHook) {
+		hook.coercion = &integerCoercion{keyPatterns: keyPatterns}
+	}
+}
+
+// WithFeatureFlagPrefix sets the prefix of the entry fields holding feature
+// flags, "feature:" by default. A field such as "feature:new_checkout" set to
+// "enabled" reports the flag new_checkout with the variant "enabled" in the
+// "featureFlags" tab instead of the "metadata" tab. An empty prefix disables
+// feature flags.
+func WithFeatureFlagPrefix(prefix string) Option {
+	return func(hook *bugsnagHook) {
+		hook.featureFlagPrefix = prefix
+	}
+}
+
+// WithHTTPRequestContext reports the value of the HTTP header with the given
+// name, such as "X-Request-ID", as the "id" field of the "request" tab. The
+// value is read from the context of the entry, where it is stored by
+// RequestIDMiddleware.
+func WithHTTPRequestContext(headerName string) Option {
+	return func(hook *bugsnagHook) {
+		hook.requestIDHeader = http.CanonicalHeaderKey(headerName)
+	}
+}
+
+// WithReleaseFields makes the entry fields with the given names, such as
+// "release_stage" and "app_version", override the release stage and the app
+// version of the events reporting the entry. The fields are not sent as
+// metadata. Fields whose values are not non-empty strings are ignored. An
+// empty name disables the corresponding override.
+func WithReleaseFields(releaseStageField, appVersionField string) Option {
+	return func(hook *bugsnagHook) {
+		hook.releaseStageField = releaseStageField
+		hook.appVersionField = appVersionField
+	}
+}
+
+// WithStageProfiles applies the profile of the release stage of the hook,
+// such as ProductionDefaults for "production", when the hook is created. The
+// profile is applied before the other options, whatever their order, so that
+// they take precedence over it; each option is still applied once.
+// Profiles are selected once: RefreshConfig does not change the profile.
+func WithStageProfiles(profiles map[string]Profile) Option {
+	return func(hook *bugsnagHook) {
+		hook.profiles = profiles
+	}
+}
+
+// WithBeforeNotify calls fn with each event sent by the hook and the entry it
+// reports, before the event is delivered. Unlike bugsnag.OnBeforeNotify, fn
+// only sees the events of this hook. Returning an error cancels the event,
+// which is then counted as ignored. fn is not called for the notifiers set
+// with WithNotifier, which send no bugsnag.Event.
+func WithBeforeNotify(fn func(event *bugsnag.Event, entry *logrus.Entry) error) Option {
+	return func(hook *bugsnagHook) {
+		hook.beforeNotify = fn
+	}
+}
+
+// WithEntryTimestampField adds the time of the entry to the "metadata" tab
+// under fieldName, in RFC 3339 format, as Bugsnag only records when it
+// receives events. It is disabled by default.
+func WithEntryTimestampField(fieldName string) Option {
+	return func(hook *bugsnagHook) {
+		hook.timestampField = fieldName
+	}
+}
+
+// WithApprovalFn calls fn with the context of each entry to report, the
+// error that would be sent and the entry, before the event is built. Entries
+// for which fn returns false are not reported, and are counted as ignored.
+// fn is called synchronously from Fire, so it should be fast.
+func WithApprovalFn(fn func(ctx context.Context, notifyErr error, entry *logrus.Entry) bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.approve = fn
+	}
+}
+
+// WithAutoDetectProject adds a "source" tab to events, holding the path of
+// the repository of the origin remote of the Git repository in the working
+// directory, such as "vend/logrus-bugsnag". It is read once when the hook is
+// created, by running git, and the tab is omitted if it cannot be read. Only
+// enable it in development, where the binary runs from its repository.
+func WithAutoDetectProject(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		if enabled {
+			hook.setStaticTab(sourceTab, sourceMetadata())
+		} else {
+			hook.setStaticTab(sourceTab, nil)
+		}
+	}
+}
+
+// WithFeatureFlagSuppressor does not report the entries for which fn returns
+// true, meaning that they concern a feature that is disabled, for example
+// under development. fn can check the state of the flag named by a field of
+// the entry, such as "feature_flag". Suppressed entries are counted as
+// ignored.
+func WithFeatureFlagSuppressor(fn func(entry *logrus.Entry) bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.suppressFeature = fn
+	}
+}
+
+// WithRuntimeMetadata adds a "runtime" tab to events with the number of
+// goroutines and memory statistics of the process when the entry is fired,
+// like WithMetadataPlugins(RuntimeStatsPlugin{}). Reading the memory
+// statistics stops the world briefly for each event reported.
+func WithRuntimeMetadata(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.setPlugin(RuntimeStatsPlugin{}, enabled)
+	}
+}
+
+// WithPanicValue reports the value of the entry field with the given name,
+// such as "panic_value", in a "panic" tab for PanicLevel entries. If the value
+// is an error, it is reported instead of the "error" field or the message of
+// the entry.
+func WithPanicValue(fieldName string) Option {
+	return func(hook *bugsnagHook) {
+		hook.panicValueField = fieldName
+	}
+}
+
+// WithShortErrorClass strips the package names from the classes of the
+// reported errors, which are the names of their Go types: errors of type
+// *errors.ValidationError are reported as "*ValidationError".
+func WithShortErrorClass(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.shortErrorClass = enabled
+	}
+}
+
+// WithErrorClassPrefix prepends prefix, such as the name of the service, to
+// the classes of the reported errors, to tell apart the errors of services
+// reporting to the same Bugsnag project.
+func WithErrorClassPrefix(prefix string) Option {
+	return func(hook *bugsnagHook) {
+		hook.errorClassPrefix = prefix
+	}
+}
+
+// WithLogger adds the hook to logger once it is created, so that Close can
+// remove it from logger. Entries can be logged with logger while Close runs,
+// but hooks must not be added to or replaced on logger concurrently with
+// Close, as logrus offers no way to remove a hook atomically.
+func WithLogger(logger *logrus.Logger) Option {
+	return func(hook *bugsnagHook) {
+		hook.logger = logger
+	}
+}
+
+// WithNotifyTimeout abandons the synchronous deliveries of a bugsnag notifier
+// that take longer than d, such as those to a hung endpoint, so that they do
+// not block the logging goroutine indefinitely. Fire then returns an
+// ErrBugsnagSendFailed wrapping context.DeadlineExceeded, and the delivery is
+// not retried. There is no timeout by default.
+func WithNotifyTimeout(d time.Duration) Option {
+	return func(hook *bugsnagHook) {
+		hook.notifyTimeout = d
+	}
+}
+
+// WithDistributedDedup suppresses events identical to one sent by any instance
+// sharing client less than ttl ago, as WithDedupWindow does within an
+// instance. Events are identical if they have the same fingerprint, set with
+// WithFingerprintFields, or the same error type, message and top stack frame.
+// An event is sent if client sets its key; the key is not removed if the
+// delivery fails.
+func WithDistributedDedup(client CacheClient, ttl time.Duration) Option {
+	return func(hook *bugsnagHook) {
+		hook.distributedDedup = &distributedDedup{client: client, ttl: ttl}
+	}
+}
+
+// WithMultiErrors selects how the hook reports multi-errors, such as those
+// returned by errors.Join. max limits the number of errors of a multi-error
+// reported separately or described in the "errors" tab; it defaults to 5 if it
+// is not positive.
+func WithMultiErrors(mode MultiErrorMode, max int) Option {
+	return func(hook *bugsnagHook) {
+		if max <= 0 {
+			max = defaultMaxMultiErrors
+		}
+		hook.multiErrorMode = mode
+		hook.maxMultiErrors = max
+	}
+}
+
+// WithMaxStackDepth sends at most n stack frames, from the top of the stack
+// traces, to keep the payloads of deeply recursive code small. Stack traces
+// are not truncated if n is not positive.
+func WithMaxStackDepth(n int) Option {
+	return func(hook *bugsnagHook) {
+		hook.maxStackDepth = n
+	}
+}
+
+// WithSuppressedClientStatusCodes does not report the errors of HTTP clients
+// carrying a response with one of the given status codes, such as 404 or 429
+// for expected and handled failures. The response is found by looking for a
+// ResponseError in the chain of the reported error.
+func WithSuppressedClientStatusCodes(codes ...int) Option {
+	return func(hook *bugsnagHook) {
+		if hook.suppressedStatusCodes == nil {
+			hook.suppressedStatusCodes = make(map[int]struct{}, len(codes))
+		}
+		for _, code := range codes {
+			hook.suppressedStatusCodes[code] = struct{}{}
+		}
+	}
+}
+
+// WithSilentFailures makes Fire return nil rather than an ErrBugsnagSendFailed
+// when an event fails to be delivered, so that logrus does not print the
+// failure to stderr for every entry while Bugsnag is unreachable. Failures are
+// still counted in Stats and passed to the function set with WithOnSendError.
+func WithSilentFailures(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.silentFailures = enabled
+	}
+}
+
+// WithErrorChainTypes adds the messages of the errors of the reported error's
+// chain, as found with errors.Unwrap, whose types match one of targets to an
+// "error_chain_types" tab, keyed by their position in the chain. Unlike the
+// "error_chain" field added by WithErrorChain, which lists every message of
+// the chain, the tab only holds the matching errors. As with errors.As,
+// each target is a pointer to a variable of the type to match, such as
+// new(*url.Error), or of an interface type the errors must implement. Other
+// targets are ignored.
+func WithErrorChainTypes(targets ...interface{}) Option {
+	return func(hook *bugsnagHook) {
+		for _, target := range targets {
+			typ := reflect.TypeOf(target)
+			if typ == nil || typ.Kind() != reflect.Ptr {
+				continue
+			}
+			hook.errorChainTypes = append(hook.errorChainTypes, typ.Elem())
+		}
+	}
+}
+
+// WithRuntimeInfo adds the hostname, the Go version, the operating system and
+// architecture, and the number of goroutines to a "runtime" tab, like
+// WithMetadataPlugins(NewRuntimeInfoPlugin()). Unlike WithRuntimeMetadata, it
+// does not read memory statistics, so it is cheap enough for every event.
+func WithRuntimeInfo(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.setPlugin(NewRuntimeInfoPlugin(), enabled)
+	}
+}
+
+// WithRetryJitter adds a random duration of up to maxJitter to the delay
+// before each retry set with WithRetry, so that instances failing together do
+// not retry together. The duration is drawn from crypto/rand.
+func WithRetryJitter(maxJitter time.Duration) Option {
+	return func(hook *bugsnagHook) {
+		hook.retry.maxJitter = maxJitter
+	}
+}
+
+// WithIgnoreErrors does not report the errors that are, or wrap, one of errs,
+// as found with errors.Is, such as io.EOF or sql.ErrNoRows when they are
+// expected.
+func WithIgnoreErrors(errs ...error) Option {
+	return func(hook *bugsnagHook) {
+		hook.ignoredErrors = append(hook.ignoredErrors, errs...)
+	}
+}
+
+// WithIgnoreErrorTypes does not report the errors that are, or wrap, an error
+// of one of types, as found with errors.As, such as
+// reflect.TypeOf((*net.OpError)(nil)). A type may be an interface type, such
+// as reflect.TypeOf((*net.Error)(nil)).Elem(). Types that are neither
+// interface types nor implement error are ignored.
+func WithIgnoreErrorTypes(types ...reflect.Type) Option {
+	return func(hook *bugsnagHook) {
+		for _, typ := range types {
+			if typ != nil && (typ.Kind() == reflect.Interface || typ.Implements(errorType)) {
+				hook.ignoredErrorTypes = append(hook.ignoredErrorTypes, typ)
+			}
+		}
+	}
+}
+
+// WithSampler sends each event with the probability returned by fn for its
+// entry and error, such as 0.01 to send 1 in 100 of the events of an expected
+// error. Events are always sent by default. The events not sampled are counted
+// as ignored, and the sent events carry the probability they had to be sent
+// as "sample_rate" in the "metadata" tab, so that counts can be extrapolated.
+func WithSampler(fn func(entry *logrus.Entry, notifyErr error) float64) Option {
+	return func(hook *bugsnagHook) {
+		hook.sampler = newSampler(fn)
+	}
+}
+
+// WithIgnoreErrorsFunc does not report the events for which fn returns true,
+// given the entry and the reported error, which is made from the message of
+// the entry if it has no "error" field. It can be given several times: an
+// event is not reported if any of the functions returns true.
+func WithIgnoreErrorsFunc(fn func(entry *logrus.Entry, err error) bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.ignoreFuncs = append(hook.ignoreFuncs, fn)
+	}
+}
+
+// WithIgnorableError does not report the errors matched by match, in addition
+// to context cancellations, which are never reported. It can be given several
+// times: an error is not reported if any of the matchers matches it.
+// IsGRPCCanceled matches the cancellations of gRPC calls.
+func WithIgnorableError(match func(err error) bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.ignorableMatchers = append(hook.ignorableMatchers, match)
+	}
+}
+
+// WithTextMarshalerErrors sends the text returned by the MarshalText method of
+// the errors implementing encoding.TextMarshaler as their message, instead of
+// the message returned by their Error method, which is kept as "go_error" in
+// an "error" tab. Errors failing to marshal are sent as usual. It is disabled
+// by default.
+func WithTextMarshalerErrors(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.textMarshalerErrors = enabled
+	}
+}
+
+// WithTypedFields encodes the entry fields holding structs, or values
+// implementing json.Marshaler, with encoding/json, and sends them as decoded
+// from their encoding, so that the types of their fields do not depend on the
+// serialization of Bugsnag: integers stay numeric, time.Time values become
+// RFC 3339 strings and []byte values base64 strings. With WithTabPromotion,
+// such fields become tabs. It is disabled by default.
+func WithTypedFields(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.typedFields = enabled
+	}
+}
+
+// WithRuntimeVersionOverride adds versions, such as those of the native
+// libraries embedded by the process, to the runtime versions of the device of
+// events, along with the Go version. As bugsnag-go sets the device of events
+// itself, the versions are sent as "runtimeVersions" in a "device" tab, which
+// Bugsnag shows with the device. A version named "go" overrides the Go
+// version.
+func WithRuntimeVersionOverride(versions map[string]string) Option {
+	return func(hook *bugsnagHook) {
+		device := map[string]interface{}{}
+		for key, val := range hook.staticTabs[deviceTab] {
+			device[key] = val
+		}
+		device["runtimeVersions"] = runtimeVersions(versions)
+		hook.setStaticTab(deviceTab, device)
+	}
+}
+
+// WithNotifyReleaseStages notifies the events only in the given release
+// stages, overriding the NotifyReleaseStages of the bugsnag configuration. The
+// release stage of an event is that of the field set with WithReleaseFields,
+// if any, or else that of the configuration. Events in other stages are
+// counted as ignored. Events are notified if the release stage is not set.
+func WithNotifyReleaseStages(stages ...string) Option {
+	return func(hook *bugsnagHook) {
+		hook.notifyReleaseStages = append([]string{}, stages...)
+	}
+}
+
+// WithUnhandledLevels reports the entries at the given levels as unhandled
+// errors, which Bugsnag counts against the stability score of the release,
+// and the other entries as handled errors. By default, only the entries at the
+// "Panic" level are unhandled, as logged by Panic in a recovery path about to
+// crash the goroutine; add logrus.FatalLevel for the entries logged before the
+// process exits. WithPanicValue adds the value the entry recovered.
+func WithUnhandledLevels(levels ...logrus.Level) Option {
+	return func(hook *bugsnagHook) {
+		hook.unhandledLevels = append([]logrus.Level(nil), levels...)
+	}
+}
+
+// WithNilFieldDropping leaves out of the metadata the entry fields holding a
+// nil interface value, such as a nil error logged with
+// WithField("err", err), which bugsnag would otherwise send as the string
+// "<nil>". It is disabled by default.
+func WithNilFieldDropping(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.dropNilFields = enabled
+	}
+}
+
+// WithSpillDirectory writes the events that are not delivered when the hook
+// is closed, because their delivery failed or is still in progress, to a new
+// file of dir, as JSON lines. ReplayPending sends them again, for example when
+// the next instance starts, skipping those older than maxAge, unless maxAge
+// is zero. Up to 1000 failed events are kept, the oldest being evicted.
+// Events still in progress when the hook is closed may be delivered twice.
+func WithSpillDirectory(dir string, maxAge time.Duration) Option {
+	return func(hook *bugsnagHook) {
+		if dir == "" {
+			hook.spill = nil
+			return
+		}
+		hook.spill = newSpill(dir, maxAge)
+	}
+}
+
+// WithErrorChannel sends an ErrBugsnagSendFailed to ch whenever an event fails
+// to be delivered, after any retries, so that monitoring systems can alert
+// when Bugsnag is unreachable. The error is dropped if ch is full, so that
+// deliveries never block on it: give ch a buffer.
+func WithErrorChannel(ch chan<- error) Option {
+	return func(hook *bugsnagHook) {
+		hook.errorChannel = ch
+	}
+}
+
+// WithTagPrefix collects the entry fields whose keys start with prefix, such
+// as "tag.", into a "tags" tab, under their keys without the prefix, so that
+// log.WithField("tag.region", "eu-west-1") tags the event with the region
+// without adding to the "metadata" tab. Bugsnag has no tags of its own, but
+// events can be searched by the fields of a tab.
+func WithTagPrefix(prefix string) Option {
+	return func(hook *bugsnagHook) {
+		hook.tagPrefix = prefix
+	}
+}
+
+// WithMetadataLimits caps the metadata of events, so that entries with
+// oversized fields are reported with partial metadata rather than failing to
+// be delivered. Strings longer than maxValueBytes, including nested ones,
+// byte slices and the messages of errors and fmt.Stringers, are truncated
+// with a "...(truncated, N bytes)" suffix. Fields beyond maxKeys, across all tabs, are dropped, and their
+// number set as "_truncated_keys" in their tabs. Then, while the estimated
+// size of the metadata exceeds maxBytes, its largest values are replaced with
+// "(truncated, N bytes)". The defaults are 16KiB, 128 fields and 512KiB. Caps
+// that are not positive are not enforced.
+func WithMetadataLimits(maxValueBytes, maxKeys, maxBytes int) Option {
+	return func(hook *bugsnagHook) {
+		hook.limits = metadataLimits{maxValueBytes: maxValueBytes, maxKeys: maxKeys, maxBytes: maxBytes}
+	}
+}
+
+// WithSectionNameSanitizer sets the function returning the name of the
+// Bugsnag tab, or section, promoted from an entry field with WithTabPromotion,
+// given the name of the field. Fields whose tab would be named "metadata" are
+// nested in the "metadata" tab instead. By default, whitespace is trimmed,
+// characters other than letters, digits, spaces, '.', '-' and '_' are
+// replaced with underscores, and empty names become "metadata". A nil fn
+// keeps the names of fields as they are.
+func WithSectionNameSanitizer(fn func(string) string) Option {
+	return func(hook *bugsnagHook) {
+		hook.tabNameSanitizer = fn
+	}
+}
+
+// WithMessageErrorExtractor sets the function finding the error to report in
+// the message of entries without an error field, such as those logged with
+// Errorf("dial %s: %v", addr, err), so that their events get a meaningful
+// error class. extract returns the error, or nil to report the message as
+// usual, and the rest of the message, which is added to the "logrus" tab as
+// "message". It is not called for entries with an empty message.
+func WithMessageErrorExtractor(extract func(msg string) (error, string)) Option {
+	return func(hook *bugsnagHook) {
+		hook.messageErrorExtractor = extract
+	}
+}
+
+// WithCombinedMessages reports entries with both an error field and a message,
+// such as those logged with WithError(err).Errorf("processing order %s
+// failed", id), with the message "<message>: <error>" instead of the message
+// of the error alone. The error class is unchanged. It is disabled by default.
+func WithCombinedMessages(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.combineMessages = enabled
+	}
+}
+
+// WithSQLNullableUnwrapping sends entry fields holding the nullable types of
+// database/sql, such as sql.NullString or sql.NullInt64, as their value, or as
+// the string "NULL" if they are NULL, rather than as {"String": "val",
+// "Valid": true}. sql.NullTime values are sent as RFC 3339 strings. It is
+// disabled by default.
+func WithSQLNullableUnwrapping(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.unwrapSQLNulls = enabled
+	}
+}
+
+// WithDryRun builds the events of the hook as usual but, instead of sending
+// them to Bugsnag, logs their error class and message with the logger of the
+// bugsnag configuration. Events logged this way are counted as ignored. It is
+// disabled by default.
+func WithDryRun(enabled bool) Option {
+	return func(hook *bugsnagHook) {
+		hook.dryRun = enabled
+	}
+}
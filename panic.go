@@ -0,0 +1,37 @@
+package logrus_bugsnag
+
+import (
+	"fmt"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+)
+
+// panicTab is the metadata tab holding the panic value of PanicLevel
+// entries.
+const panicTab = "panic"
+
+// isPanicField reports whether the entry field with the given name holds the
+// panic value of the entry.
+func (hook *bugsnagHook) isPanicField(entry *logrus.Entry, key string) bool {
+	return key != "" && key == hook.panicValueField && entry.Level == logrus.PanicLevel
+}
+
+// panicValue returns the panic value of a PanicLevel entry, and whether it is
+// set.
+func (hook *bugsnagHook) panicValue(entry *logrus.Entry) (interface{}, bool) {
+	if hook.panicValueField == "" || entry.Level != logrus.PanicLevel {
+		return nil, false
+	}
+	val, ok := entry.Data[hook.panicValueField]
+	return val, ok
+}
+
+// addPanicValue adds the panic value of the entry, if any, to the "panic"
+// tab.
+func (hook *bugsnagHook) addPanicValue(metadata bugsnag.MetaData, entry *logrus.Entry) {
+	if val, ok := hook.panicValue(entry); ok {
+		metadata.Add(panicTab, "value", fmt.Sprintf("%+v", val))
+		metadata.Add(panicTab, "type", fmt.Sprintf("%T", val))
+	}
+}
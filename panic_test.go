@@ -0,0 +1,40 @@
+package logrus_bugsnag
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPanicValue(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithPanicValue("panic_value"))
+	defer teardown()
+
+	cause := fmt.Errorf("index out of range [%d]", 3)
+	assert.Panics(t, func() {
+		log.WithFields(logrus.Fields{"panic_value": cause, "animal": "walrus"}).Panic("recovered")
+	})
+	event := receiveEvent(t, c)
+	assert.Equal(t, "index out of range [3]", event.Exceptions[0].Message)
+	assert.Equal(t, "*errors.errorString", event.Exceptions[0].ErrorClass)
+	assert.Equal(t, map[string]interface{}{"value": "index out of range [3]", "type": "*errors.errorString"},
+		event.Metadata["panic"])
+	assert.Equal(t, map[string]interface{}{"animal": "walrus"}, event.Metadata["metadata"])
+
+	// Panic values that are not errors are only added to the metadata.
+	assert.Panics(t, func() {
+		log.WithField("panic_value", []int{1, 2}).Panic("recovered")
+	})
+	event = receiveEvent(t, c)
+	assert.Equal(t, "recovered", event.Exceptions[0].Message)
+	assert.Equal(t, map[string]interface{}{"value": "[1 2]", "type": "[]int"}, event.Metadata["panic"])
+
+	// The field is an ordinary field at other levels.
+	log.WithField("panic_value", cause).Error("failed")
+	event = receiveEvent(t, c)
+	assert.Equal(t, "failed", event.Exceptions[0].Message)
+	assert.NotContains(t, event.Metadata, "panic")
+	assert.Contains(t, event.Metadata["metadata"], "panic_value")
+}
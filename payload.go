@@ -0,0 +1,181 @@
+package logrus_bugsnag
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// largestTabs and frequentFields are the number of tabs and fields
+	// reported in Stats.
+	largestTabs    = 5
+	frequentFields = 10
+	// maxTrackedKeys bounds the number of distinct tabs and fields tracked,
+	// so that fields with unbounded names cannot grow the stats forever.
+	maxTrackedKeys = 1000
+)
+
+// TabSize is the average estimated size of a metadata tab.
+type TabSize struct {
+	Name     string
+	AvgBytes float64
+}
+
+// FieldCount is the number of events a metadata field was sent with.
+type FieldCount struct {
+	Key   string
+	Count int64
+}
+
+// payloadStats aggregates the sizes and fields of the events' metadata since
+// the hook was created.
+type payloadStats struct {
+	mu         sync.Mutex
+	events     int64
+	totalBytes int64
+	maxBytes   int64
+	tabs       map[string]*tabTotal
+	fields     map[string]int64
+}
+
+type tabTotal struct {
+	events int64
+	bytes  int64
+}
+
+// record adds the metadata of an event to the stats, given the estimated
+// size of each of its tabs, as returned by metadataLimits.apply.
+func (p *payloadStats) record(metadata bugsnag.MetaData, tabSizes map[string]int) {
+	size := payloadSize(tabSizes)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tabs == nil {
+		p.tabs = make(map[string]*tabTotal)
+		p.fields = make(map[string]int64)
+	}
+	p.events++
+	p.totalBytes += int64(size)
+	if int64(size) > p.maxBytes {
+		p.maxBytes = int64(size)
+	}
+	for tab, tabSize := range tabSizes {
+		total := p.tabs[tab]
+		if total == nil {
+			if len(p.tabs) >= maxTrackedKeys {
+				continue
+			}
+			total = &tabTotal{}
+			p.tabs[tab] = total
+		}
+		total.events++
+		total.bytes += int64(tabSize)
+	}
+	for _, fields := range metadata {
+		for key := range fields {
+			if _, ok := p.fields[key]; ok || len(p.fields) < maxTrackedKeys {
+				p.fields[key]++
+			}
+		}
+	}
+}
+
+// fill sets the payload aggregates of s.
+func (p *payloadStats) fill(s *Stats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.events == 0 {
+		return
+	}
+	s.AvgPayloadBytes = float64(p.totalBytes) / float64(p.events)
+	s.MaxPayloadBytes = p.maxBytes
+
+	tabs := make([]TabSize, 0, len(p.tabs))
+	for name, total := range p.tabs {
+		tabs = append(tabs, TabSize{Name: name, AvgBytes: float64(total.bytes) / float64(total.events)})
+	}
+	sort.Slice(tabs, func(i, j int) bool {
+		if tabs[i].AvgBytes != tabs[j].AvgBytes {
+			return tabs[i].AvgBytes > tabs[j].AvgBytes
+		}
+		return tabs[i].Name < tabs[j].Name
+	})
+	if len(tabs) > largestTabs {
+		tabs = tabs[:largestTabs]
+	}
+	s.LargestTabs = tabs
+
+	fields := make([]FieldCount, 0, len(p.fields))
+	for key, count := range p.fields {
+		fields = append(fields, FieldCount{Key: key, Count: count})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Count != fields[j].Count {
+			return fields[i].Count > fields[j].Count
+		}
+		return fields[i].Key < fields[j].Key
+	})
+	if len(fields) > frequentFields {
+		fields = fields[:frequentFields]
+	}
+	s.FrequentFields = fields
+}
+
+// payloadSize returns the estimated size of metadata serialized to JSON,
+// given the estimated size of each of its tabs.
+func payloadSize(tabSizes map[string]int) int {
+	size := 2
+	for tab, tabSize := range tabSizes {
+		size += len(tab) + 4 + tabSize
+	}
+	return size
+}
+
+// estimateSize approximates the size of val serialized to JSON, without
+// serializing it.
+func estimateSize(val interface{}) int {
+	switch v := val.(type) {
+	case nil:
+		return 4
+	case string:
+		return len(v) + 2
+	case bool:
+		return 5
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return 8
+	case error:
+		return len(v.Error()) + 2
+	case map[string]interface{}:
+		return estimateMapSize(v)
+	case logrus.Fields:
+		return estimateMapSize(v)
+	case []interface{}:
+		size := 2
+		for _, elem := range v {
+			size += estimateSize(elem) + 1
+		}
+		return size
+	case []string:
+		size := 2
+		for _, elem := range v {
+			size += len(elem) + 3
+		}
+		return size
+	case fmt.Stringer:
+		return len(v.String()) + 2
+	default:
+		return len(fmt.Sprint(v)) + 2
+	}
+}
+
+func estimateMapSize(m map[string]interface{}) int {
+	size := 2
+	for key, val := range m {
+		size += len(key) + 4 + estimateSize(val)
+	}
+	return size
+}
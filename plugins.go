@@ -0,0 +1,135 @@
+package logrus_bugsnag
+
+import (
+	"os"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+)
+
+// MetadataPlugin adds metadata to the events of a hook created with
+// WithMetadataPlugins.
+type MetadataPlugin interface {
+	// Name identifies the plugin.
+	Name() string
+	// Enrich returns the metadata of the event reporting entry, after adding
+	// to or modifying md.
+	Enrich(entry *logrus.Entry, md bugsnag.MetaData) bugsnag.MetaData
+}
+
+// enrich passes the metadata through the hook's plugins, in order.
+func (hook *bugsnagHook) enrich(entry *logrus.Entry, md bugsnag.MetaData) bugsnag.MetaData {
+	for _, plugin := range hook.plugins {
+		if enriched := plugin.Enrich(entry, md); enriched != nil {
+			md = enriched
+		}
+	}
+	return md
+}
+
+// setPlugin adds plugin to the plugins of the hook if enabled is set, or
+// removes it otherwise. Plugins of the same type as plugin are replaced.
+func (hook *bugsnagHook) setPlugin(plugin MetadataPlugin, enabled bool) {
+	plugins := hook.plugins[:0:0]
+	for _, p := range hook.plugins {
+		if reflect.TypeOf(p) != reflect.TypeOf(plugin) {
+			plugins = append(plugins, p)
+		}
+	}
+	if enabled {
+		plugins = append(plugins, plugin)
+	}
+	hook.plugins = plugins
+}
+
+// RuntimeStatsPlugin adds a "runtime" tab with the number of goroutines and
+// memory statistics of the process when the event is sent. Reading the memory
+// statistics stops the world briefly, which adds to the latency of every
+// goroutine each time an event is reported: avoid it in processes reporting
+// many events.
+type RuntimeStatsPlugin struct{}
+
+// Name returns "runtime".
+func (RuntimeStatsPlugin) Name() string {
+	return "runtime"
+}
+
+// Enrich adds the "runtime" tab.
+func (RuntimeStatsPlugin) Enrich(entry *logrus.Entry, md bugsnag.MetaData) bugsnag.MetaData {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	md.Update(bugsnag.MetaData{"runtime": {
+		"goroutines":       runtime.NumGoroutine(),
+		"heap_alloc_bytes": mem.HeapAlloc,
+		"heap_objects":     mem.HeapObjects,
+		"sys_bytes":        mem.Sys,
+		"num_gc":           mem.NumGC,
+	}})
+	return md
+}
+
+// RuntimeInfoPlugin adds the hostname, the Go version, the operating system
+// and architecture, and the number of goroutines when the event is sent to
+// the "runtime" tab. Create it with NewRuntimeInfoPlugin.
+type RuntimeInfoPlugin struct {
+	hostname string
+}
+
+// NewRuntimeInfoPlugin returns a RuntimeInfoPlugin, reading the hostname once.
+func NewRuntimeInfoPlugin() RuntimeInfoPlugin {
+	hostname, _ := os.Hostname()
+	return RuntimeInfoPlugin{hostname: hostname}
+}
+
+// Name returns "runtime_info".
+func (RuntimeInfoPlugin) Name() string {
+	return "runtime_info"
+}
+
+// Enrich adds the runtime information to the "runtime" tab.
+func (p RuntimeInfoPlugin) Enrich(entry *logrus.Entry, md bugsnag.MetaData) bugsnag.MetaData {
+	info := map[string]interface{}{
+		"go_version": runtime.Version(),
+		"goos":       runtime.GOOS,
+		"goarch":     runtime.GOARCH,
+		"goroutines": runtime.NumGoroutine(),
+	}
+	if p.hostname != "" {
+		info["hostname"] = p.hostname
+	}
+	md.Update(bugsnag.MetaData{"runtime": info})
+	return md
+}
+
+// BuildInfoPlugin adds a "build" tab with the Go version, the main module and
+// the version control information the binary was built with.
+type BuildInfoPlugin struct{}
+
+// Name returns "build".
+func (BuildInfoPlugin) Name() string {
+	return "build"
+}
+
+// Enrich adds the "build" tab, if the binary has build information.
+func (BuildInfoPlugin) Enrich(entry *logrus.Entry, md bugsnag.MetaData) bugsnag.MetaData {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return md
+	}
+	build := map[string]interface{}{
+		"go_version":   info.GoVersion,
+		"main_path":    info.Main.Path,
+		"main_version": info.Main.Version,
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision", "vcs.time", "vcs.modified":
+			build[setting.Key] = setting.Value
+		}
+	}
+	md.Update(bugsnag.MetaData{"build": build})
+	return md
+}
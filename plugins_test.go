@@ -0,0 +1,85 @@
+package logrus_bugsnag
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tenantPlugin is a third-party plugin adding the tenant of the entry, and
+// recording the plugins that ran before it.
+type tenantPlugin struct{}
+
+func (tenantPlugin) Name() string {
+	return "tenant"
+}
+
+func (tenantPlugin) Enrich(entry *logrus.Entry, md bugsnag.MetaData) bugsnag.MetaData {
+	_, hasRuntime := md["runtime"]
+	md.Add("tenant", "id", entry.Data["tenant_id"])
+	md.Add("tenant", "after_runtime", hasRuntime)
+	return md
+}
+
+func TestMetadataPlugins(t *testing.T) {
+	log, _, notifier := newFakeLogger(t, WithMetadataPlugins(RuntimeStatsPlugin{}, tenantPlugin{}, BuildInfoPlugin{}))
+	log.WithField("tenant_id", "acme").Error("plugins")
+
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	metadata := calls[0].metadata()
+	assert.Equal(t, map[string]interface{}{"id": "acme", "after_runtime": true}, metadata["tenant"])
+	assert.Equal(t, "acme", metadata["metadata"]["tenant_id"])
+	assert.NotZero(t, metadata["runtime"]["goroutines"])
+	assert.NotZero(t, metadata["runtime"]["heap_alloc_bytes"])
+	assert.Equal(t, runtime.Version(), metadata["build"]["go_version"])
+}
+
+func TestWithRuntimeMetadata(t *testing.T) {
+	log, hook, notifier := newFakeLogger(t, WithRuntimeMetadata(true), WithRuntimeMetadata(true))
+	assert.Len(t, hook.plugins, 1)
+	log.Error("runtime")
+
+	calls := notifier.sent()
+	require.Len(t, calls, 1)
+	stats := calls[0].metadata()["runtime"]
+	assert.True(t, stats["goroutines"].(int) >= 1)
+	assert.NotZero(t, stats["heap_alloc_bytes"])
+	assert.Contains(t, stats, "num_gc")
+
+	log, _, notifier = newFakeLogger(t, WithRuntimeMetadata(true), WithRuntimeMetadata(false))
+	log.Error("no runtime")
+	calls = notifier.sent()
+	require.Len(t, calls, 1)
+	assert.NotContains(t, calls[0].metadata(), "runtime")
+}
+
+func TestWithRuntimeInfo(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithRuntimeInfo(true), WithRuntimeMetadata(true),
+		WithStaticMetadata("build", map[string]interface{}{"commit": "abc123"}))
+	defer teardown()
+
+	log.WithField("runtime", "entry field").Error("runtime info")
+	metadata := receiveEvent(t, c).Metadata
+	info := metadata["runtime"]
+	hostname, _ := os.Hostname()
+	assert.Equal(t, hostname, info["hostname"])
+	assert.Equal(t, runtime.Version(), info["go_version"])
+	assert.Equal(t, runtime.GOOS, info["goos"])
+	assert.Equal(t, runtime.GOARCH, info["goarch"])
+	assert.NotZero(t, info["goroutines"])
+	// The tab is shared with RuntimeStatsPlugin.
+	assert.Contains(t, info, "heap_alloc_bytes")
+	assert.Equal(t, map[string]interface{}{"commit": "abc123"}, metadata["build"])
+	assert.Equal(t, map[string]interface{}{"runtime": "entry field"}, metadata["metadata"])
+
+	log, _, c, teardown = newTestLogger(t, WithRuntimeInfo(true), WithRuntimeInfo(false))
+	defer teardown()
+	log.Error("no runtime info")
+	assert.NotContains(t, receiveEvent(t, c).Metadata, "runtime")
+}
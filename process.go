@@ -0,0 +1,40 @@
+package logrus_bugsnag
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+const processTab = "process"
+
+// deviceTab is shown by Bugsnag along with the device of the event.
+const deviceTab = "device"
+
+// processMetadata returns the metadata describing the running process. It is
+// computed once when the hook is created.
+func processMetadata() map[string]interface{} {
+	process := map[string]interface{}{
+		"pid": os.Getpid(),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		process["hostname"] = hostname
+	}
+	if len(os.Args) > 0 {
+		process["binary"] = os.Args[0]
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		process["build_path"] = info.Path
+	}
+	return process
+}
+
+// runtimeVersions returns the Go version merged with versions, which override
+// it.
+func runtimeVersions(versions map[string]string) map[string]interface{} {
+	merged := map[string]interface{}{"go": runtime.Version()}
+	for name, version := range versions {
+		merged[name] = version
+	}
+	return merged
+}
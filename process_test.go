@@ -0,0 +1,70 @@
+package logrus_bugsnag
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessMetadata(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithProcessMetadata(true))
+	defer teardown()
+
+	log.Error("process")
+	process := receiveEvent(t, c).Metadata["process"]
+	hostname, err := os.Hostname()
+	assert.NoError(t, err)
+	assert.Equal(t, float64(os.Getpid()), process["pid"])
+	assert.Equal(t, hostname, process["hostname"])
+	assert.Equal(t, os.Args[0], process["binary"])
+	if info, ok := debug.ReadBuildInfo(); ok {
+		assert.Equal(t, info.Path, process["build_path"])
+	}
+
+	log, _, c, teardown = newTestLogger(t)
+	defer teardown()
+
+	log.Error("no process")
+	assert.NotContains(t, receiveEvent(t, c).Metadata, "process")
+}
+
+func TestStaticMetadata(t *testing.T) {
+	build := map[string]interface{}{"commit": "abc123", "tags": []string{"release"}, "pod": map[string]interface{}{"name": "api-1"}}
+	log, _, c, teardown := newTestLogger(t, WithStaticMetadata("build", build), WithStaticMetadata("empty", nil))
+	defer teardown()
+	build["commit"] = "changed"
+	build["tags"].([]string)[0] = "changed"
+	build["pod"].(map[string]interface{})["name"] = "changed"
+
+	log.WithField("commit", "entry").Error("static")
+	metadata := receiveEvent(t, c).Metadata
+	assert.Equal(t, map[string]interface{}{
+		"commit": "abc123",
+		"tags":   []interface{}{"release"},
+		"pod":    map[string]interface{}{"name": "api-1"},
+	}, metadata["build"])
+	assert.Equal(t, map[string]interface{}{"commit": "entry"}, metadata["metadata"])
+	assert.NotContains(t, metadata, "empty")
+}
+
+func TestRuntimeVersionOverride(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t,
+		WithStaticMetadata("device", map[string]interface{}{"model": "m5.large"}),
+		WithRuntimeVersionOverride(map[string]string{"openssl": "1.1.1", "sqlite": "3.39.0"}),
+	)
+	defer teardown()
+
+	log.Error("versions")
+	event := receiveEvent(t, c)
+	assert.Equal(t, map[string]interface{}{
+		"model": "m5.large",
+		"runtimeVersions": map[string]interface{}{
+			"go":      runtime.Version(),
+			"openssl": "1.1.1",
+			"sqlite":  "3.39.0",
+		},
+	}, event.Metadata["device"])
+}
@@ -0,0 +1,70 @@
+package logrus_bugsnag
+
+import (
+	"reflect"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Profile is a set of options applied together, such as the options suited
+// to a release stage.
+type Profile []Option
+
+// ProductionDefaults returns the profile suited to production: identical
+// events are deduplicated for a minute, at most 10 events per second are sent
+// with bursts of 50, 1 in 10 warnings is sent while errors are always sent,
+// as described in WithSampler, and failed deliveries are retried up to 3
+// times. The default fields are redacted, as in every profile. Payloads are
+// not compressed, as not every collector accepts compressed payloads; add
+// WithCompression(true) to the profile if yours does.
+func ProductionDefaults() Profile {
+	return Profile{
+		WithDedupWindow(time.Minute),
+		WithRateLimit(10, 50),
+		WithSampler(sampleWarnings),
+		WithRetry(3, time.Second),
+	}
+}
+
+// sampleWarnings is the sampler of ProductionDefaults.
+func sampleWarnings(entry *logrus.Entry, _ error) float64 {
+	if entry.Level >= logrus.WarnLevel {
+		return 0.1
+	}
+	return 1
+}
+
+// DevelopmentDefaults returns the profile suited to local development: the
+// hook runs dry, logging the events it would send to Bugsnag rather than
+// sending them, as described in WithDryRun.
+func DevelopmentDefaults() Profile {
+	return Profile{
+		WithDryRun(true),
+	}
+}
+
+// stageProfilesCode is the code of the options returned by WithStageProfiles.
+var stageProfilesCode = reflect.ValueOf(WithStageProfiles(nil)).Pointer()
+
+// isStageProfiles reports whether opt was returned by WithStageProfiles, for
+// the profiles to be selected before the other options are applied.
+func isStageProfiles(opt Option) bool {
+	return opt != nil && reflect.ValueOf(opt).Pointer() == stageProfilesCode
+}
+
+// releaseStage returns the release stage of the hook created with notifier,
+// or with the global bugsnag configuration if notifier is nil.
+func releaseStage(notifier *bugsnag.Notifier) string {
+	if notifier != nil && notifier.Config != nil {
+		return notifier.Config.ReleaseStage
+	}
+	return bugsnag.Config.ReleaseStage
+}
+
+// SelectedProfile returns the release stage whose profile was applied when
+// the hook was created, or "" if none was.
+func (hook *bugsnagHook) SelectedProfile() string {
+	return hook.profile
+}
@@ -0,0 +1,129 @@
+package logrus_bugsnag
+
+import (
+	"bytes"
+	"io/ioutil"
+	stdlog "log"
+	"testing"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
+	"golang.org/x/time/rate"
+)
+
+func TestStageProfiles(t *testing.T) {
+	profiles := map[string]Profile{
+		"production":  ProductionDefaults(),
+		"development": DevelopmentDefaults(),
+	}
+	_, hook, _, teardown := newTestLogger(t, WithStageProfiles(profiles))
+	defer teardown()
+
+	assert.Equal(t, "production", hook.SelectedProfile())
+	assert.False(t, hook.compress)
+	require.NotNil(t, hook.limiter)
+	assert.Equal(t, time.Minute, hook.limiter.window)
+	assert.Equal(t, retryPolicy{maxAttempts: 3, baseDelay: time.Second}, hook.retry)
+	assert.True(t, hook.isActive())
+
+	config := hook.EffectiveConfig()
+	assert.Equal(t, "production", config.Profile)
+	assert.Equal(t, "production", config.ReleaseStage)
+	assert.Equal(t, time.Minute, config.DedupWindow)
+	assert.Equal(t, rate.Limit(10), config.RateLimit)
+	assert.Equal(t, 50, config.RateBurst)
+	assert.Equal(t, 3, config.RetryAttempts)
+	assert.False(t, config.Compression)
+	assert.True(t, config.Sampled)
+	assert.False(t, config.DryRun)
+	assert.Equal(t, "production", hook.Status().Profile)
+}
+
+func TestStageProfilesOverride(t *testing.T) {
+	profiles := map[string]Profile{"production": append(ProductionDefaults(), WithCompression(true))}
+	_, hook, _, teardown := newTestLogger(t,
+		WithCompression(false), WithStageProfiles(profiles), WithDedupWindow(time.Second))
+	defer teardown()
+
+	config := hook.EffectiveConfig()
+	assert.Equal(t, "production", config.Profile)
+	assert.False(t, config.Compression, "options passed before the profiles must override them")
+	assert.Equal(t, time.Second, config.DedupWindow, "options passed after the profiles must override them")
+	assert.Equal(t, 3, config.RetryAttempts)
+	assert.Equal(t, rate.Limit(10), config.RateLimit)
+}
+
+func TestStageProfilesAppliedOnce(t *testing.T) {
+	var applied, profileApplied int
+	count := func(n *int) Option {
+		return func(*bugsnagHook) { *n++ }
+	}
+	profiles := map[string]Profile{"production": {count(&profileApplied)}}
+	_, hook, _, teardown := newTestLogger(t, count(&applied), WithStageProfiles(profiles), count(&applied))
+	defer teardown()
+
+	assert.Equal(t, "production", hook.SelectedProfile())
+	assert.Equal(t, 2, applied)
+	assert.Equal(t, 1, profileApplied)
+}
+
+func TestProductionSampling(t *testing.T) {
+	warning := &logrus.Entry{Level: logrus.WarnLevel}
+	assert.Equal(t, 0.1, sampleWarnings(warning, nil))
+	for _, level := range []logrus.Level{logrus.ErrorLevel, logrus.FatalLevel, logrus.PanicLevel} {
+		assert.Equal(t, 1.0, sampleWarnings(&logrus.Entry{Level: level}, nil), level)
+	}
+}
+
+func TestStageProfilesNoMatch(t *testing.T) {
+	_, hook, _, teardown := newTestLogger(t, WithStageProfiles(map[string]Profile{"development": DevelopmentDefaults()}))
+	defer teardown()
+
+	assert.Empty(t, hook.SelectedProfile())
+	assert.True(t, hook.isActive())
+	assert.Nil(t, hook.limiter)
+}
+
+func TestStageProfilesNotifier(t *testing.T) {
+	notifier := bugsnag.New(bugsnag.Configuration{
+		APIKey:       "12345678901234567890123456789012",
+		ReleaseStage: "development",
+	})
+	hook, err := NewBugsnagHookWithNotifier(notifier, WithRegistry(nil),
+		WithStageProfiles(map[string]Profile{"development": DevelopmentDefaults()}))
+	require.NoError(t, err)
+
+	assert.Equal(t, "development", hook.SelectedProfile())
+	assert.True(t, hook.isActive())
+	status := hook.Status()
+	assert.Equal(t, "development", status.Profile)
+	assert.True(t, status.DryRun)
+	assert.True(t, hook.EffectiveConfig().DryRun)
+}
+
+func TestDryRun(t *testing.T) {
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	var logged bytes.Buffer
+	notifier := bugsnag.New(bugsnag.Configuration{
+		APIKey:      bugsnagtest.APIKey,
+		Endpoints:   c.Endpoints(),
+		Synchronous: true,
+		Logger:      stdlog.New(&logged, "", 0),
+	})
+	hook, err := NewBugsnagHookWithNotifier(notifier, WithRegistry(nil), WithName("dry"), WithDryRun(true))
+	require.NoError(t, err)
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+
+	log.WithError(paymentDeclined{code: "card_expired"}).Error("payment failed")
+	assert.Contains(t, logged.String(), `logrus_bugsnag: dry run of hook "dry": PaymentDeclined: payment declined: card_expired`)
+	assert.Equal(t, int64(1), hook.Stats().Ignored)
+	assert.Equal(t, int64(0), hook.Stats().Sent)
+	assert.Empty(t, c.Pending())
+}
@@ -0,0 +1,136 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+)
+
+// QuarantinedEvent is an event that could not be delivered, kept for
+// inspection and for RetryQuarantined.
+type QuarantinedEvent struct {
+	Event *FinalizedEvent
+	// Err is the error of the last delivery attempt.
+	Err error
+	// At is when the event was quarantined.
+	At time.Time
+}
+
+// quarantine holds the events that exhausted their delivery attempts, up to
+// a capacity beyond which the oldest are evicted.
+type quarantine struct {
+	capacity int
+
+	mu     sync.Mutex
+	events []QuarantinedEvent
+}
+
+// add quarantines events, and returns the number of events evicted to make
+// room for them.
+func (q *quarantine) add(events ...QuarantinedEvent) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.events = append(q.events, events...)
+	evicted := len(q.events) - q.capacity
+	if evicted <= 0 {
+		return 0
+	}
+	q.events = append([]QuarantinedEvent(nil), q.events[evicted:]...)
+	return evicted
+}
+
+func (q *quarantine) list() []QuarantinedEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]QuarantinedEvent(nil), q.events...)
+}
+
+func (q *quarantine) takeAll() []QuarantinedEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	events := q.events
+	q.events = nil
+	return events
+}
+
+// quarantineEvent quarantines an event that could not be delivered, if
+// quarantine is enabled.
+func (hook *bugsnagHook) quarantineEvent(err *bugsnag_errors.Error, rawData []interface{}, sendErr error) {
+	if hook.quarantine == nil {
+		return
+	}
+	hook.stats.quarantined.Add(1)
+	evicted := hook.quarantine.add(QuarantinedEvent{
+		Event: &FinalizedEvent{Error: err, RawData: rawData},
+		Err:   sendErr,
+		At:    time.Now(),
+	})
+	hook.stats.evicted.Add(int64(evicted))
+}
+
+// Quarantined returns the events that could not be delivered, oldest first.
+// They are only kept with WithQuarantine.
+func (hook *bugsnagHook) Quarantined() []QuarantinedEvent {
+	if hook.quarantine == nil {
+		return nil
+	}
+	return hook.quarantine.list()
+}
+
+// RetryQuarantined makes one more attempt to deliver each quarantined event,
+// for example after fixing the cause of their failures. The events that fail
+// again stay quarantined, and their errors are joined in the returned error.
+// If ctx is done, RetryQuarantined stops and returns ctx.Err(). If the hook
+// was created with WithDeferredConfigCheck and bugsnag is not configured yet,
+// the events stay quarantined and ErrBugsnagUnconfigured is returned.
+func (hook *bugsnagHook) RetryQuarantined(ctx context.Context) error {
+	if hook.quarantine == nil {
+		return nil
+	}
+	if hook.sender == nil {
+		if err := hook.checkConfigured(); err != nil {
+			return err
+		}
+	}
+	events := hook.quarantine.takeAll()
+	var failed []QuarantinedEvent
+	var errs []error
+	for i, q := range events {
+		if err := ctx.Err(); err != nil {
+			failed = append(failed, events[i:]...)
+			errs = append(errs, err)
+			break
+		}
+		if err := hook.sendNow(ctx, q.Event); err != nil {
+			q.Err = err
+			failed = append(failed, q)
+			errs = append(errs, err)
+			continue
+		}
+		hook.stats.released.Add(1)
+		hook.stats.sent.Add(1)
+	}
+	// Put the failed events back before those quarantined meanwhile.
+	requeued := append(failed, hook.quarantine.takeAll()...)
+	hook.stats.evicted.Add(int64(hook.quarantine.add(requeued...)))
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return errors.Join(errs...)
+}
+
+// sendNow sends event synchronously with the hook's notifier, bounded by ctx.
+// It returns ErrBugsnagUnconfigured if the hook has no notifier yet.
+func (hook *bugsnagHook) sendNow(ctx context.Context, event *FinalizedEvent) error {
+	if hook.sender != nil {
+		return event.Notify(hook.sender)
+	}
+	if err := hook.checkConfigured(); err != nil {
+		return err
+	}
+	notifier, _ := hook.notifiers()
+	return event.Notify(synchronous{withContext(notifier, ctx)})
+}
@@ -0,0 +1,95 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
+)
+
+func TestQuarantine(t *testing.T) {
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	var working int32
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&working) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c.ServeHTTP(w, r)
+	}))
+	defer endpoint.Close()
+
+	log, hook := newRecordingLogger(t, endpoint.URL, WithRetry(2, time.Millisecond), WithQuarantine(2))
+	for _, msg := range []string{"poison 1", "poison 2", "poison 3"} {
+		log.Error(msg)
+		// Wait for the retries, to quarantine the events in order.
+		require.NoError(t, hook.Flush(context.Background()))
+	}
+
+	// The oldest event was evicted.
+	quarantined := hook.Quarantined()
+	require.Len(t, quarantined, 2)
+	for i, q := range quarantined {
+		assert.Equal(t, []string{"poison 2", "poison 3"}[i], q.Event.Error.Error())
+		assert.Error(t, q.Err)
+		assert.False(t, q.At.IsZero())
+	}
+	stats := hook.Stats()
+	assert.Equal(t, int64(3), stats.Failed)
+	assert.Equal(t, int64(3), stats.Retries)
+	assert.Equal(t, int64(3), stats.Quarantined)
+	assert.Equal(t, int64(1), stats.Evicted)
+
+	// The events stay quarantined while they fail.
+	assert.Error(t, hook.RetryQuarantined(context.Background()))
+	assert.Len(t, hook.Quarantined(), 2)
+
+	atomic.StoreInt32(&working, 1)
+	require.NoError(t, hook.RetryQuarantined(context.Background()))
+	assert.Empty(t, hook.Quarantined())
+	assert.Equal(t, "poison 2", receiveEvent(t, c).Exceptions[0].Message)
+	assert.Equal(t, "poison 3", receiveEvent(t, c).Exceptions[0].Message)
+	stats = hook.Stats()
+	assert.Equal(t, int64(2), stats.Released)
+	assert.Equal(t, int64(2), stats.Sent)
+	assert.Equal(t, int64(3), stats.Quarantined)
+}
+
+func TestRetryQuarantinedUnconfigured(t *testing.T) {
+	apiKey := bugsnag.Config.APIKey
+	bugsnag.Config.APIKey = ""
+	defer func() {
+		bugsnag.Config.APIKey = apiKey
+	}()
+	hook, err := NewBugsnagHook(WithRegistry(nil), WithDeferredConfigCheck(false), WithQuarantine(2))
+	require.NoError(t, err)
+	event, err := hook.NewEventBuilder().WithMessage("quarantined").Build()
+	require.NoError(t, err)
+	hook.quarantineEvent(event.Error, event.RawData, errors.New("rejected"))
+
+	// The events stay quarantined until bugsnag is configured.
+	assert.Equal(t, ErrBugsnagUnconfigured, hook.RetryQuarantined(context.Background()))
+	assert.Len(t, hook.Quarantined(), 1)
+
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	configureBugsnag(c.Endpoints())
+	require.NoError(t, hook.RetryQuarantined(context.Background()))
+	assert.Empty(t, hook.Quarantined())
+	assert.Equal(t, "quarantined", receiveEvent(t, c).Exceptions[0].Message)
+}
+
+func TestQuarantineDisabled(t *testing.T) {
+	_, hook, _ := newFakeLogger(t)
+	assert.Nil(t, hook.Quarantined())
+	assert.NoError(t, hook.RetryQuarantined(context.Background()))
+}
@@ -0,0 +1,65 @@
+package logrus_bugsnag
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redactedValue = "[REDACTED]"
+
+// defaultRedactedFields are always redacted from metadata, mirroring the
+// spirit of bugsnag's own ParamsFilters.
+var defaultRedactedFields = []string{"password", "secret", "token", "authorization"}
+
+// redactor replaces the values of sensitive metadata fields.
+type redactor struct {
+	// fields holds lower-cased substrings of field names to redact.
+	fields []string
+	fn     func(key string, value interface{}) (interface{}, bool)
+}
+
+func newRedactor() redactor {
+	r := redactor{}
+	r.addFields(defaultRedactedFields...)
+	return r
+}
+
+func (r *redactor) addFields(fields ...string) {
+	for _, field := range fields {
+		r.fields = append(r.fields, strings.ToLower(field))
+	}
+}
+
+// redact returns the value to send to Bugsnag for the given field. Maps and
+// slices are copied rather than modified or sent in place, since they may
+// still be in use by the caller while the event is delivered.
+func (r *redactor) redact(key string, value interface{}) interface{} {
+	lowerKey := strings.ToLower(key)
+	for _, field := range r.fields {
+		if strings.Contains(lowerKey, field) {
+			return redactedValue
+		}
+	}
+	if r.fn != nil {
+		if replacement, ok := r.fn(key, value); ok {
+			return replacement
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return r.redactMap(v)
+	case logrus.Fields:
+		return r.redactMap(v)
+	}
+	return deepCopyValue(value)
+}
+
+func (r *redactor) redactMap(m map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(m))
+	for key, val := range m {
+		redacted[key] = r.redact(key, val)
+	}
+	return redacted
+}
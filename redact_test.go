@@ -0,0 +1,56 @@
+package logrus_bugsnag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	r := newRedactor()
+	r.addFields("Card_Number")
+	r.fn = func(key string, value interface{}) (interface{}, bool) {
+		if s, ok := value.(string); ok && strings.HasPrefix(s, "sk_") {
+			return "[API KEY]", true
+		}
+		return nil, false
+	}
+
+	nested := logrus.Fields{
+		"Authorization": "Bearer abc",
+		"user":          "walrus",
+		"inner": map[string]interface{}{
+			"cardNumber":  "ignored because of the underscore",
+			"CARD_NUMBER": "4111111111111111",
+			"key":         "sk_live_123",
+		},
+	}
+	fields := map[string]interface{}{
+		"UserPassword": "hunter2",
+		"apiToken":     "abc",
+		"size":         9009,
+		"request":      nested,
+	}
+
+	redacted := r.redactMap(fields)
+	assert.Equal(t, map[string]interface{}{
+		"UserPassword": redactedValue,
+		"apiToken":     redactedValue,
+		"size":         9009,
+		"request": map[string]interface{}{
+			"Authorization": redactedValue,
+			"user":          "walrus",
+			"inner": map[string]interface{}{
+				"cardNumber":  "ignored because of the underscore",
+				"CARD_NUMBER": redactedValue,
+				"key":         "[API KEY]",
+			},
+		},
+	}, redacted)
+
+	// The caller's maps must not be modified.
+	assert.Equal(t, "hunter2", fields["UserPassword"])
+	assert.Equal(t, "Bearer abc", nested["Authorization"])
+}
@@ -0,0 +1,156 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Registry tracks hooks so they can be flushed and shut down together, for
+// example when the process exits.
+type Registry struct {
+	mu    sync.Mutex
+	hooks map[*bugsnagHook]struct{}
+}
+
+// DefaultRegistry is the registry hooks are added to when they are created,
+// unless another one is chosen with WithRegistry. Hooks are removed from it
+// when they are shut down.
+var DefaultRegistry = &Registry{}
+
+// hookCount is used to name hooks created without WithName.
+var hookCount int64
+
+func (r *Registry) add(hook *bugsnagHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.hooks == nil {
+		r.hooks = make(map[*bugsnagHook]struct{})
+	}
+	r.hooks[hook] = struct{}{}
+}
+
+func (r *Registry) remove(hook *bugsnagHook) {
+	r.mu.Lock()
+	delete(r.hooks, hook)
+	r.mu.Unlock()
+}
+
+func (r *Registry) list() []*bugsnagHook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hooks := make([]*bugsnagHook, 0, len(r.hooks))
+	for hook := range r.hooks {
+		hooks = append(hooks, hook)
+	}
+	return hooks
+}
+
+// FlushAll flushes all the hooks of the registry concurrently, sharing the
+// deadline of ctx. The returned error joins the errors of the hooks that
+// failed to flush, each prefixed with the name of the hook.
+func (r *Registry) FlushAll(ctx context.Context) error {
+	return r.each(func(hook *bugsnagHook) error {
+		return hook.Flush(ctx)
+	})
+}
+
+// CloseAll shuts down all the hooks of the registry concurrently, sharing the
+// deadline of ctx. The returned error joins the errors of the hooks that
+// failed to flush, each prefixed with the name of the hook.
+func (r *Registry) CloseAll(ctx context.Context) error {
+	return r.each(func(hook *bugsnagHook) error {
+		return hook.Shutdown(ctx)
+	})
+}
+
+func (r *Registry) each(fn func(*bugsnagHook) error) error {
+	hooks := r.list()
+	errs := make([]error, len(hooks))
+	var wg sync.WaitGroup
+	for i, hook := range hooks {
+		wg.Add(1)
+		go func(i int, hook *bugsnagHook) {
+			defer wg.Done()
+			if err := fn(hook); err != nil {
+				errs[i] = fmt.Errorf("bugsnag hook %q: %w", hook.name, err)
+			}
+		}(i, hook)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// FlushAll flushes all the hooks of DefaultRegistry.
+func FlushAll(ctx context.Context) error {
+	return DefaultRegistry.FlushAll(ctx)
+}
+
+// CloseAll shuts down all the hooks of DefaultRegistry.
+func CloseAll(ctx context.Context) error {
+	return DefaultRegistry.CloseAll(ctx)
+}
+
+// Shutdown stops the hook from reporting new entries, removes it from its
+// registry and flushes it. With WithSpillDirectory, the events that are not
+// delivered are then written to disk.
+func (hook *bugsnagHook) Shutdown(ctx context.Context) error {
+	hook.mu.Lock()
+	hook.closed = true
+	hook.mu.Unlock()
+	if hook.registry != nil {
+		hook.registry.remove(hook)
+	}
+	return errors.Join(hook.Flush(ctx), hook.spillPending())
+}
+
+// Close stops the hook from reporting new entries, removes it from its
+// registry and removes it from the logger it was added to with WithLogger, if
+// any, which must not have hooks added or replaced concurrently. Unlike Shutdown, it does not wait for pending deliveries, which still
+// complete in the background. With WithSpillDirectory, the events that are
+// not delivered yet are written to disk, and Close returns the error writing
+// them.
+func (hook *bugsnagHook) Close() error {
+	hook.mu.Lock()
+	hook.closed = true
+	hook.mu.Unlock()
+	if hook.registry != nil {
+		hook.registry.remove(hook)
+	}
+	if hook.logger != nil {
+		removeHook(hook.logger, hook)
+	}
+	return hook.spillPending()
+}
+
+// removeHook removes hook from all the levels of logger. The hooks of logger
+// are read without its lock, which logrus does not expose, so the caller must
+// not run it concurrently with AddHook or ReplaceHooks on logger, whose hooks
+// would be lost. Entries logged concurrently only read the hooks, and fire
+// either the old or the new ones.
+func removeHook(logger *logrus.Logger, hook logrus.Hook) {
+	hooks := make(logrus.LevelHooks)
+	for level, levelHooks := range logger.Hooks {
+		for _, h := range levelHooks {
+			if h != hook {
+				hooks[level] = append(hooks[level], h)
+			}
+		}
+	}
+	logger.ReplaceHooks(hooks)
+}
+
+func (hook *bugsnagHook) isClosed() bool {
+	hook.mu.RLock()
+	defer hook.mu.RUnlock()
+	return hook.closed
+}
+
+// defaultHookName returns a name for a hook created without WithName.
+func defaultHookName() string {
+	return fmt.Sprintf("hook-%d", atomic.AddInt64(&hookCount, 1))
+}
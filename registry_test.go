@@ -0,0 +1,172 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
+)
+
+// countingHook counts the entries it fires.
+type countingHook struct {
+	fired int64
+}
+
+func (h *countingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *countingHook) Fire(*logrus.Entry) error {
+	atomic.AddInt64(&h.fired, 1)
+	return nil
+}
+
+// newAsyncLogger returns a logger with a hook delivering events
+// asynchronously to url.
+func newAsyncLogger(t *testing.T, url string, opts ...Option) (*logrus.Logger, *bugsnagHook) {
+	notifier := bugsnag.New(bugsnag.Configuration{
+		APIKey:    "12345678901234567890123456789012",
+		Endpoints: bugsnag.Endpoints{Notify: url, Sessions: url},
+	})
+	// The notifier inherits Synchronous from the global configuration.
+	notifier.Config.Synchronous = false
+	hook, err := NewBugsnagHookWithNotifier(notifier, opts...)
+	require.NoError(t, err, "failed to create hook")
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	log.Hooks.Add(hook)
+	return log, hook
+}
+
+func TestRegistry(t *testing.T) {
+	c1 := bugsnagtest.NewCapture()
+	defer c1.Close()
+	c2 := bugsnagtest.NewCapture()
+	defer c2.Close()
+	unblock := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer hanging.Close()
+	defer close(unblock)
+
+	registry := &Registry{}
+	log1, _ := newAsyncLogger(t, c1.NotifyURL(), WithRegistry(registry), WithName("tenant-1"))
+	log2, _ := newAsyncLogger(t, c2.NotifyURL(), WithRegistry(registry), WithName("tenant-2"))
+	log3, _ := newAsyncLogger(t, hanging.URL, WithRegistry(registry), WithName("tenant-3"))
+	log1.Error("tenant 1")
+	log2.Error("tenant 2")
+	log3.Error("tenant 3")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := registry.FlushAll(ctx)
+	assert.True(t, time.Since(start) < time.Second, "FlushAll did not respect the deadline")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, strings.Contains(err.Error(), `"tenant-3"`), err.Error())
+	assert.False(t, strings.Contains(err.Error(), `"tenant-1"`), err.Error())
+
+	// The other hooks were flushed despite the hanging one.
+	assert.Equal(t, "tenant 1", receiveEvent(t, c1).Exceptions[0].Message)
+	assert.Equal(t, "tenant 2", receiveEvent(t, c2).Exceptions[0].Message)
+}
+
+func TestRegistryShutdown(t *testing.T) {
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+
+	registry := &Registry{}
+	log, hook := newAsyncLogger(t, c.NotifyURL(), WithRegistry(registry))
+	_, other := newAsyncLogger(t, c.NotifyURL(), WithRegistry(registry))
+	_, unregistered := newAsyncLogger(t, c.NotifyURL(), WithRegistry(nil))
+	assert.ElementsMatch(t, []*bugsnagHook{hook, other}, registry.list())
+
+	log.Error("before shutdown")
+	require.NoError(t, hook.Shutdown(context.Background()))
+	assert.Equal(t, "before shutdown", receiveEvent(t, c).Exceptions[0].Message)
+	assert.Equal(t, []*bugsnagHook{other}, registry.list())
+
+	// A shut down hook no longer reports entries.
+	log.Error("after shutdown")
+	require.NoError(t, hook.Flush(context.Background()))
+	assert.Empty(t, c.Pending())
+
+	require.NoError(t, registry.CloseAll(context.Background()))
+	assert.Empty(t, registry.list())
+	assert.False(t, unregistered.isClosed())
+}
+
+func TestClose(t *testing.T) {
+	log, unbound, c, teardown := newTestLogger(t)
+	defer teardown()
+
+	registry := &Registry{}
+	other := logrus.New()
+	other.Out = ioutil.Discard
+	hook, err := NewBugsnagHookWithWarnings(WithRegistry(registry), WithLogger(other))
+	require.NoError(t, err)
+	assert.Len(t, other.Hooks[logrus.WarnLevel], 1)
+	assert.Len(t, other.Hooks[logrus.ErrorLevel], 1)
+
+	other.Error("before close")
+	assert.Equal(t, "before close", receiveEvent(t, c).Exceptions[0].Message)
+
+	require.NoError(t, hook.Close())
+	assert.Empty(t, registry.list())
+	for _, level := range logrus.AllLevels {
+		assert.NotContains(t, other.Hooks[level], hook)
+	}
+	other.Error("after close")
+	require.NoError(t, hook.Flush(context.Background()))
+	assert.Empty(t, c.Pending())
+	assert.Equal(t, int64(1), hook.Stats().Attempted)
+
+	// Hooks added to loggers by their callers stop reporting entries.
+	require.NoError(t, unbound.Close())
+	log.Error("unbound")
+	assert.Empty(t, c.Pending())
+}
+
+func TestCloseWhileLogging(t *testing.T) {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	counter := &countingHook{}
+	logger.AddHook(counter)
+	hook, err := NewBugsnagHook(WithRegistry(nil), WithNotifier(&fakeNotifier{}), WithLogger(logger))
+	require.NoError(t, err)
+
+	const goroutines, entries = 4, 50
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < entries; j++ {
+				logger.Error("failed")
+			}
+		}()
+	}
+	require.NoError(t, hook.Close())
+	wg.Wait()
+
+	// The other hooks of the logger are kept, and fired for every entry.
+	assert.Equal(t, int64(goroutines*entries), atomic.LoadInt64(&counter.fired))
+	for _, level := range logrus.AllLevels {
+		assert.Equal(t, logrus.Hook(counter), logger.Hooks[level][0])
+		assert.NotContains(t, logger.Hooks[level], hook)
+	}
+}
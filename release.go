@@ -0,0 +1,54 @@
+package logrus_bugsnag
+
+import (
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+)
+
+// isReleaseField reports whether the entry field with the given name
+// overrides the release stage or the app version of the event.
+func (hook *bugsnagHook) isReleaseField(key string) bool {
+	return key != "" && (key == hook.releaseStageField || key == hook.appVersionField)
+}
+
+// releaseOverride returns the configuration overriding the release stage and
+// the app version of the event with the entry's fields, and the release stages
+// notified with those of the hook. Fields that are not non-empty strings are
+// ignored. It returns false if there is no override.
+func (hook *bugsnagHook) releaseOverride(entry *logrus.Entry) (bugsnag.Configuration, bool) {
+	var config bugsnag.Configuration
+	if hook.releaseStageField != "" {
+		config.ReleaseStage, _ = entry.Data[hook.releaseStageField].(string)
+	}
+	if hook.appVersionField != "" {
+		config.AppVersion, _ = entry.Data[hook.appVersionField].(string)
+	}
+	config.NotifyReleaseStages = hook.notifyReleaseStages
+	return config, config.ReleaseStage != "" || config.AppVersion != "" || config.NotifyReleaseStages != nil
+}
+
+// notifiesReleaseStage reports whether the hook notifies events reporting
+// entry in their release stage: the stage of the entry's field, or else the
+// stage of the hook's configuration. Events are notified in all stages unless
+// the hook was created with WithNotifyReleaseStages, and in an unset stage.
+func (hook *bugsnagHook) notifiesReleaseStage(entry *logrus.Entry) bool {
+	if hook.notifyReleaseStages == nil {
+		return true
+	}
+	_, base := hook.notifiers()
+	stage := releaseStage(base)
+	if hook.releaseStageField != "" {
+		if override, _ := entry.Data[hook.releaseStageField].(string); override != "" {
+			stage = override
+		}
+	}
+	if stage == "" {
+		return true
+	}
+	for _, notified := range hook.notifyReleaseStages {
+		if notified == stage {
+			return true
+		}
+	}
+	return false
+}
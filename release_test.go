@@ -0,0 +1,75 @@
+package logrus_bugsnag
+
+import (
+	"io/ioutil"
+	"testing"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
+)
+
+func TestWithReleaseFields(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithReleaseFields("release_stage", "app_version"))
+	defer teardown()
+
+	log.WithFields(logrus.Fields{"release_stage": "canary", "app_version": "1.2.3", "animal": "walrus"}).Error("failed")
+	event := receiveEvent(t, c)
+	assert.Equal(t, bugsnagtest.App{ReleaseStage: "canary", Version: "1.2.3"}, event.App)
+	assert.Equal(t, map[string]interface{}{"animal": "walrus"}, event.Metadata["metadata"])
+
+	log.WithField("app_version", "1.2.4").Error("failed")
+	event = receiveEvent(t, c)
+	assert.Equal(t, bugsnagtest.App{ReleaseStage: "production", Version: "1.2.4"}, event.App)
+
+	log.WithFields(logrus.Fields{"release_stage": 42, "app_version": ""}).Error("failed")
+	event = receiveEvent(t, c)
+	assert.Equal(t, bugsnagtest.App{ReleaseStage: "production"}, event.App)
+	assert.Empty(t, event.Metadata["metadata"])
+}
+
+func TestReleaseFieldsDisabled(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t)
+	defer teardown()
+
+	log.WithField("release_stage", "canary").Error("failed")
+	event := receiveEvent(t, c)
+	assert.Equal(t, "production", event.App.ReleaseStage)
+	assert.Equal(t, map[string]interface{}{"release_stage": "canary"}, event.Metadata["metadata"])
+}
+
+func TestWithNotifyReleaseStages(t *testing.T) {
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	newLogger := func(opts ...Option) (*logrus.Logger, *bugsnagHook) {
+		notifier := bugsnag.New(bugsnag.Configuration{
+			APIKey:              "12345678901234567890123456789012",
+			Endpoints:           c.Endpoints(),
+			ReleaseStage:        "development",
+			NotifyReleaseStages: []string{"production"},
+			Synchronous:         true,
+		})
+		hook, err := NewBugsnagHookWithNotifier(notifier, append([]Option{WithRegistry(nil)}, opts...)...)
+		require.NoError(t, err)
+		log := logrus.New()
+		log.Out = ioutil.Discard
+		log.Hooks.Add(hook)
+		return log, hook
+	}
+
+	// The global filter drops the events of the development stage.
+	log, _ := newLogger()
+	log.Error("filtered globally")
+	assert.Empty(t, c.Pending())
+
+	log, hook := newLogger(WithNotifyReleaseStages("development", "staging"), WithReleaseFields("release_stage", ""))
+	log.Error("development")
+	assert.Equal(t, "development", receiveEvent(t, c).Exceptions[0].Message)
+	log.WithField("release_stage", "staging").Error("staging")
+	assert.Equal(t, "staging", receiveEvent(t, c).Exceptions[0].Message)
+	log.WithField("release_stage", "production").Error("production")
+	assert.Empty(t, c.Pending())
+	assert.Equal(t, int64(1), hook.Stats().Ignored)
+}
@@ -0,0 +1,44 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"net/http"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+)
+
+const requestTab = "request"
+
+// requestHeaderKey is the context key of the value of an HTTP request header,
+// stored by RequestIDMiddleware.
+type requestHeaderKey string
+
+// RequestIDMiddleware stores the value of the request's header with the
+// given name, such as "X-Request-ID", in the request context. Entries logged
+// with this context by a hook created with WithHTTPRequestContext report the
+// value as the request ID.
+func RequestIDMiddleware(headerName string) func(http.Handler) http.Handler {
+	key := requestHeaderKey(http.CanonicalHeaderKey(headerName))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id := r.Header.Get(string(key)); id != "" {
+				r = r.WithContext(context.WithValue(r.Context(), key, id))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// addRequestID adds the request ID stored in the entry's context by
+// RequestIDMiddleware to the "request" tab.
+func (hook *bugsnagHook) addRequestID(metadata bugsnag.MetaData, entry *logrus.Entry) {
+	if hook.requestIDHeader == "" || entry.Context == nil {
+		return
+	}
+	id, ok := entry.Context.Value(requestHeaderKey(hook.requestIDHeader)).(string)
+	if !ok {
+		return
+	}
+	mergeTab(metadata, requestTab, map[string]interface{}{"id": id})
+}
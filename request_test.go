@@ -0,0 +1,28 @@
+package logrus_bugsnag
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithHTTPRequestContext(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithHTTPRequestContext("x-request-id"))
+	defer teardown()
+
+	handler := RequestIDMiddleware("X-Request-ID")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.WithContext(r.Context()).Error("checkout failed")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	event := receiveEvent(t, c)
+	assert.Equal(t, "req-1", event.Metadata[requestTab]["id"])
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+	event = receiveEvent(t, c)
+	assert.NotContains(t, event.Metadata, requestTab)
+}
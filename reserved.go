@@ -0,0 +1,34 @@
+package logrus_bugsnag
+
+import "github.com/sirupsen/logrus"
+
+// Reserved entry fields controlling the reporting of the entry. They are
+// never sent to Bugsnag.
+const (
+	// SkipField set to true prevents the entry from being reported.
+	SkipField = "bugsnag.skip"
+	// ForceField set to true reports an entry below the levels of the hook,
+	// if the hook was created with WithForceField.
+	ForceField = "bugsnag.force"
+)
+
+func isReservedField(key string) bool {
+	return key == SkipField || key == ForceField
+}
+
+// fieldSet reports whether the entry's field is set to true.
+func fieldSet(entry *logrus.Entry, key string) bool {
+	set, _ := entry.Data[key].(bool)
+	return set
+}
+
+// reportsLevel reports whether the hook reports entries at level without
+// being forced.
+func (hook *bugsnagHook) reportsLevel(level logrus.Level) bool {
+	for _, l := range hook.levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package logrus_bugsnag
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipField(t *testing.T) {
+	log, hook, c, teardown := newTestLogger(t)
+	defer teardown()
+
+	log.WithField(SkipField, true).Error("business rule violated")
+	assert.Empty(t, c.Pending())
+
+	log.WithFields(logrus.Fields{SkipField: false, ForceField: true, "animal": "walrus"}).Error("reported")
+	metadata := receiveEvent(t, c).Metadata["metadata"]
+	assert.Equal(t, map[string]interface{}{"animal": "walrus"}, metadata)
+	assert.Equal(t, int64(1), hook.Stats().Ignored)
+}
+
+func TestForceField(t *testing.T) {
+	log, hook, c, teardown := newTestLogger(t, WithForceField(true))
+	defer teardown()
+	assert.Equal(t, logrus.AllLevels, hook.Levels())
+
+	log.Info("not reported")
+	log.WithField(ForceField, true).Info("forced")
+	event := receiveEvent(t, c)
+	assert.Equal(t, "forced", event.Exceptions[0].Message)
+	assert.Equal(t, "info", event.Severity)
+	assert.NotContains(t, event.Metadata["metadata"], ForceField)
+
+	log.Error("reported")
+	event = receiveEvent(t, c)
+	assert.Equal(t, "reported", event.Exceptions[0].Message)
+	assert.Equal(t, Stats{Attempted: 2, Sent: 2}, counters(hook.Stats()))
+}
@@ -0,0 +1,74 @@
+package logrus_bugsnag
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"math/rand"
+	"time"
+
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+)
+
+// retryPolicy configures how failed deliveries are retried. The zero value
+// makes a single attempt.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	// maxJitter is the maximum random duration added to each delay.
+	maxJitter time.Duration
+}
+
+// maxRetryDelay caps the doubling of the base delay of a retryPolicy.
+const maxRetryDelay = time.Minute
+
+// delay returns the backoff before the given retry, counting from 1: the base
+// delay doubled for each previous retry, up to maxRetryDelay or the base delay
+// if larger, of which a random half is waited, plus up to the maximum jitter.
+func (p retryPolicy) delay(retry int) time.Duration {
+	limit := maxRetryDelay
+	if p.baseDelay > limit {
+		limit = p.baseDelay
+	}
+	base := p.baseDelay
+	for i := 1; i < retry && base < limit; i++ {
+		base *= 2
+	}
+	if base > limit {
+		base = limit
+	}
+	var d time.Duration
+	if base > 0 {
+		d = base/2 + time.Duration(rand.Int63n(int64(base/2)+1))
+	}
+	if p.maxJitter > 0 {
+		d += jitter(p.maxJitter)
+	}
+	return d
+}
+
+// jitter returns a random duration between zero and max, from crypto/rand so
+// that instances started together are not seeded alike.
+func jitter(max time.Duration) time.Duration {
+	n, err := crand.Int(crand.Reader, big.NewInt(int64(max)+1))
+	if err != nil {
+		return 0
+	}
+	return time.Duration(n.Int64())
+}
+
+// send sends err with notifier, retrying failures as configured with
+// WithRetry. attempted is the number of attempts that already failed. It
+// returns the error of the last attempt.
+func (hook *bugsnagHook) send(notifier Notifier, err *bugsnag_errors.Error, rawData []interface{}, attempted int) error {
+	for {
+		if attempted > 0 {
+			time.Sleep(hook.retry.delay(attempted))
+			hook.stats.retries.Add(1)
+		}
+		sendErr := notifier.Notify(err, rawData...)
+		attempted++
+		if sendErr == nil || isCanceledByCallback(sendErr) || attempted >= hook.retry.maxAttempts {
+			return sendErr
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
+)
+
+func TestRetry(t *testing.T) {
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	var requests int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		c.ServeHTTP(w, r)
+	}))
+	defer flaky.Close()
+
+	log, hook := newRecordingLogger(t, flaky.URL, WithRetry(5, 10*time.Millisecond))
+	assert.NoError(t, hook.Fire(logrus.NewEntry(log).WithField("error", assert.AnError)))
+	require.NoError(t, hook.Flush(context.Background()))
+
+	assert.Equal(t, assert.AnError.Error(), receiveEvent(t, c).Exceptions[0].Message)
+	assert.Empty(t, c.Pending())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	assert.Equal(t, Stats{Attempted: 1, Sent: 1, Retries: 2}, counters(hook.Stats()))
+}
+
+func TestRetryExhausted(t *testing.T) {
+	var requests int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	var failures int32
+	onSendError := func(error, *logrus.Entry) {
+		atomic.AddInt32(&failures, 1)
+	}
+	log, hook := newAsyncLogger(t, failing.URL, WithRegistry(nil), WithRetry(3, time.Millisecond), WithOnSendError(onSendError))
+	log.Error("lost")
+	require.NoError(t, hook.Flush(context.Background()))
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&failures))
+	assert.Equal(t, Stats{Attempted: 1, Failed: 1, Retries: 2}, counters(hook.Stats()))
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 5, baseDelay: 100 * time.Millisecond}
+	for retry, max := range []time.Duration{100, 200, 400, 800} {
+		max *= time.Millisecond
+		d := policy.delay(retry + 1)
+		assert.True(t, d >= max/2 && d <= max, "retry %d: %v", retry+1, d)
+	}
+}
+
+func TestRetryDelayLimit(t *testing.T) {
+	// Doubling the base delay this many times would overflow a Duration.
+	policy := retryPolicy{maxAttempts: 100, baseDelay: time.Second}
+	for _, retry := range []int{7, 35, 64, 65, 99} {
+		d := policy.delay(retry)
+		assert.True(t, d >= maxRetryDelay/2 && d <= maxRetryDelay, "retry %d: %v", retry, d)
+	}
+
+	// A base delay above the limit is not shortened.
+	policy.baseDelay = 2 * time.Minute
+	d := policy.delay(50)
+	assert.True(t, d >= time.Minute && d <= 2*time.Minute, "delay: %v", d)
+}
+
+func TestRetryJitter(t *testing.T) {
+	_, hook, _ := newFakeLogger(t, WithRetryJitter(time.Second), WithRetry(3, 0))
+	assert.Equal(t, retryPolicy{maxAttempts: 3, maxJitter: time.Second}, hook.retry)
+
+	delays := make(map[time.Duration]bool)
+	for i := 0; i < 20; i++ {
+		d := hook.retry.delay(1)
+		assert.True(t, d >= 0 && d <= time.Second, "delay: %v", d)
+		delays[d] = true
+	}
+	assert.True(t, len(delays) > 1, "the delays are deterministic")
+
+	// Without jitter, there is no delay without a base delay.
+	assert.Equal(t, time.Duration(0), retryPolicy{maxAttempts: 3}.delay(1))
+}
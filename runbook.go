@@ -0,0 +1,63 @@
+package logrus_bugsnag
+
+import (
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// componentField is the entry field naming the component an entry
+	// originates from.
+	componentField = "component"
+
+	resolutionTab = "resolution"
+)
+
+// RunbookResolver returns the URL of the runbook for errors of the given
+// Bugsnag error class logged by the given component, or "" if there is none.
+// component is "" if the entry has no "component" field.
+type RunbookResolver func(errorClass, component string) string
+
+// RunbookMap returns a RunbookResolver looking runbooks up by error class and
+// then by component, so an error class mapping takes precedence over a
+// component mapping. Either map may be nil.
+func RunbookMap(byClass, byComponent map[string]string) RunbookResolver {
+	return func(errorClass, component string) string {
+		if url, ok := byClass[errorClass]; ok {
+			return url
+		}
+		return byComponent[component]
+	}
+}
+
+// SetRunbookResolver replaces the hook's runbook resolver while it is in use.
+// A nil resolver disables runbook links.
+func (hook *bugsnagHook) SetRunbookResolver(resolver RunbookResolver) {
+	hook.mu.Lock()
+	hook.runbookResolver = resolver
+	hook.mu.Unlock()
+}
+
+// addRunbook attaches the runbook for the event to the "resolution" tab.
+// errorClass must be the class the event is reported with. A runbook given by
+// the WithRunbookURL function takes precedence over the RunbookResolver.
+func (hook *bugsnagHook) addRunbook(metadata bugsnag.MetaData, entry *logrus.Entry, notifyErr error, errorClass string) {
+	if hook.runbookURL != nil {
+		if url := hook.runbookURL(notifyErr); url != "" {
+			metadata.Add(resolutionTab, "runbook_url", url)
+			return
+		}
+	}
+
+	hook.mu.RLock()
+	resolver := hook.runbookResolver
+	hook.mu.RUnlock()
+	if resolver == nil {
+		return
+	}
+
+	component, _ := entry.Data[componentField].(string)
+	if url := resolver(errorClass, component); url != "" {
+		metadata.Add(resolutionTab, "runbook_url", url)
+	}
+}
@@ -0,0 +1,98 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunbookMap(t *testing.T) {
+	resolve := RunbookMap(
+		map[string]string{"*errors.errorString": "https://runbooks/class"},
+		map[string]string{"billing": "https://runbooks/billing"},
+	)
+
+	assert.Equal(t, "https://runbooks/class", resolve("*errors.errorString", "billing"))
+	assert.Equal(t, "https://runbooks/billing", resolve("*url.Error", "billing"))
+	assert.Equal(t, "", resolve("*url.Error", "shipping"))
+	assert.Equal(t, "", RunbookMap(nil, nil)("*url.Error", ""))
+}
+
+func TestRunbookResolver(t *testing.T) {
+	log, hook, c, teardown := newTestLogger(t, WithRunbookResolver(RunbookMap(
+		map[string]string{"*errors.errorString": "https://runbooks/class"},
+		map[string]string{"billing": "https://runbooks/billing"},
+	)))
+	defer teardown()
+
+	log.WithFields(logrus.Fields{
+		"component": "billing",
+		"error":     errors.New("class and component both mapped"),
+	}).Error("runbook")
+	event := receiveEvent(t, c)
+	assert.Equal(t, "https://runbooks/class", event.Metadata["resolution"]["runbook_url"])
+
+	// Swapping the resolver takes effect immediately.
+	hook.SetRunbookResolver(func(errorClass, component string) string {
+		return "https://runbooks/" + component
+	})
+	log.WithField("component", "shipping").Error("runbook")
+	event = receiveEvent(t, c)
+	assert.Equal(t, "https://runbooks/shipping", event.Metadata["resolution"]["runbook_url"])
+}
+
+func TestRunbookResolverErrorClass(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t,
+		WithErrorClassPrefix("svc:"),
+		WithShortErrorClass(true),
+		WithRunbookResolver(RunbookMap(map[string]string{
+			"svc:PaymentDeclined": "https://runbooks/payments",
+			"svc:*errorString":    "https://runbooks/generic",
+		}, nil)),
+	)
+	defer teardown()
+
+	// The resolver receives the class the event is reported with.
+	log.WithError(paymentDeclined{"expired_card"}).Error("checkout failed")
+	event := receiveEvent(t, c)
+	assert.Equal(t, "svc:PaymentDeclined", event.Exceptions[0].ErrorClass)
+	assert.Equal(t, "https://runbooks/payments", event.Metadata["resolution"]["runbook_url"])
+
+	log.WithError(errors.New("boom")).Error("checkout failed")
+	event = receiveEvent(t, c)
+	assert.Equal(t, "svc:*errorString", event.Exceptions[0].ErrorClass)
+	assert.Equal(t, "https://runbooks/generic", event.Metadata["resolution"]["runbook_url"])
+}
+
+func TestRunbookURL(t *testing.T) {
+	runbookURL := func(err error) string {
+		switch err.(type) {
+		case *url.Error:
+			return "https://runbooks/network"
+		case *reconcileError:
+			return "https://runbooks/reconcile"
+		}
+		return ""
+	}
+	log, _, c, teardown := newTestLogger(t,
+		WithRunbookURL(runbookURL),
+		WithRunbookResolver(func(errorClass, component string) string {
+			return "https://runbooks/default"
+		}),
+	)
+	defer teardown()
+
+	log.WithError(&url.Error{Op: "Get", URL: "http://example.com", Err: context.DeadlineExceeded}).Error("runbook")
+	assert.Equal(t, "https://runbooks/network", receiveEvent(t, c).Metadata["resolution"]["runbook_url"])
+
+	log.WithError(&reconcileError{"stale object"}).Error("runbook")
+	assert.Equal(t, "https://runbooks/reconcile", receiveEvent(t, c).Metadata["resolution"]["runbook_url"])
+
+	// The resolver is used when the function has no runbook.
+	log.WithError(errors.New("other")).Error("runbook")
+	assert.Equal(t, "https://runbooks/default", receiveEvent(t, c).Metadata["resolution"]["runbook_url"])
+}
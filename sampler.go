@@ -0,0 +1,37 @@
+package logrus_bugsnag
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// sampler decides which events are sent, with the probability returned by
+// fn.
+type sampler struct {
+	fn func(*logrus.Entry, error) float64
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func newSampler(fn func(*logrus.Entry, error) float64) *sampler {
+	return &sampler{fn: fn, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// sample reports whether the event reporting err and entry is sent, and the
+// probability it had to be.
+func (s *sampler) sample(entry *logrus.Entry, err error) (sent bool, rate float64) {
+	rate = s.fn(entry, err)
+	switch {
+	case rate >= 1:
+		return true, 1
+	case rate <= 0:
+		return false, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Float64() < rate, rate
+}
@@ -0,0 +1,61 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+var errOptimisticLock = errors.New("optimistic lock failed")
+
+func TestWithSampler(t *testing.T) {
+	sampleLocks := func(entry *logrus.Entry, notifyErr error) float64 {
+		if errors.Is(notifyErr, errOptimisticLock) {
+			return 0.1
+		}
+		return 1
+	}
+	log, hook, c, teardown := newTestLogger(t, WithSampler(sampleLocks))
+	defer teardown()
+	hook.sampler.rng = rand.New(rand.NewSource(1))
+
+	for i := 0; i < 1000; i++ {
+		log.WithError(errOptimisticLock).Error("update failed")
+	}
+	log.Error("always sent")
+
+	// Replay the draws of the sampler.
+	rng, expected := rand.New(rand.NewSource(1)), 0
+	for i := 0; i < 1000; i++ {
+		if rng.Float64() < 0.1 {
+			expected++
+		}
+	}
+	assert.InDelta(t, 100, expected, 30)
+
+	events := c.Events()
+	assert.Len(t, events, expected+1)
+	assert.Equal(t, 0.1, events[0].Metadata["metadata"]["sample_rate"])
+	last := events[len(events)-1]
+	assert.Equal(t, "always sent", last.Exceptions[0].Message)
+	assert.Equal(t, float64(1), last.Metadata["metadata"]["sample_rate"])
+	assert.Equal(t, Stats{Attempted: 1001, Sent: int64(expected + 1), Ignored: int64(1000 - expected)}, counters(hook.Stats()))
+}
+
+func TestSamplerRates(t *testing.T) {
+	rate := 0.0
+	s := newSampler(func(*logrus.Entry, error) float64 { return rate })
+	for _, rate = range []float64{-1, 0} {
+		sent, effective := s.sample(nil, nil)
+		assert.False(t, sent)
+		assert.Equal(t, float64(0), effective)
+	}
+	for _, rate = range []float64{1, 2} {
+		sent, effective := s.sample(nil, nil)
+		assert.True(t, sent)
+		assert.Equal(t, float64(1), effective)
+	}
+}
@@ -0,0 +1,52 @@
+package logrus_bugsnag
+
+import (
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+const sourceTab = "source"
+
+// gitRemoteURL returns the URL of the origin remote of the Git repository of
+// the working directory. Tests replace it.
+var gitRemoteURL = func() (string, error) {
+	out, err := exec.Command("git", "config", "--get", "remote.origin.url").Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// sourceMetadata returns the metadata describing the source repository of
+// the process, or nil if it cannot be detected.
+func sourceMetadata() map[string]interface{} {
+	remote, err := gitRemoteURL()
+	if err != nil {
+		return nil
+	}
+	repository := repositoryName(remote)
+	if repository == "" {
+		return nil
+	}
+	return map[string]interface{}{"repository": repository}
+}
+
+// repositoryName returns the path of the repository at the given Git remote
+// URL, such as "vend/logrus-bugsnag" for
+// "git@github.com:vend/logrus-bugsnag.git". Credentials in the URL are never
+// part of the name.
+func repositoryName(remote string) string {
+	var path string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return ""
+		}
+		path = u.Path
+	} else if i := strings.Index(remote, ":"); i >= 0 {
+		// An scp-like address, such as git@github.com:vend/logrus-bugsnag.git.
+		path = remote[i+1:]
+	} else {
+		path = remote
+	}
+	path = strings.Trim(path, "/")
+	return strings.TrimSuffix(path, ".git")
+}
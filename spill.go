@@ -0,0 +1,294 @@
+package logrus_bugsnag
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+)
+
+const (
+	// spillTab holds the stack trace and the time of replayed events, as
+	// they are sent without their original stack trace.
+	spillTab = "spilled"
+	// spillPattern matches the files events are spilled to.
+	spillPattern = "logrus-bugsnag-*.jsonl"
+	// defaultSpillCapacity is the number of failed events kept for spilling,
+	// beyond which the oldest are evicted.
+	defaultSpillCapacity = 1000
+)
+
+// spilledEvent is an undelivered event as written to disk, one per line.
+type spilledEvent struct {
+	ErrorClass string           `json:"error_class"`
+	Message    string           `json:"message"`
+	Stack      []spilledFrame   `json:"stack"`
+	Metadata   bugsnag.MetaData `json:"metadata,omitempty"`
+	Timestamp  time.Time        `json:"timestamp"`
+}
+
+type spilledFrame struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Method string `json:"method"`
+}
+
+// pendingEvent is an event that is being delivered in the background, or
+// whose delivery failed.
+type pendingEvent struct {
+	err     *bugsnag_errors.Error
+	rawData []interface{}
+	at      time.Time
+}
+
+// spill keeps track of the events that are not delivered yet, to write them
+// to its directory when the hook is closed.
+type spill struct {
+	dir    string
+	maxAge time.Duration
+
+	mu       sync.Mutex
+	next     int
+	inFlight map[int]pendingEvent
+	failed   []pendingEvent
+}
+
+func newSpill(dir string, maxAge time.Duration) *spill {
+	return &spill{dir: dir, maxAge: maxAge, inFlight: make(map[int]pendingEvent)}
+}
+
+// track records an event delivered in the background, until the returned
+// function is called.
+func (s *spill) track(event pendingEvent) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.next
+	s.next++
+	s.inFlight[id] = event
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.inFlight, id)
+	}
+}
+
+// fail records an event whose delivery failed, evicting the oldest if there
+// are too many.
+func (s *spill) fail(event pendingEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = append(s.failed, event)
+	if evicted := len(s.failed) - defaultSpillCapacity; evicted > 0 {
+		s.failed = append([]pendingEvent(nil), s.failed[evicted:]...)
+	}
+}
+
+// takeAll removes the failed events and the events still in flight, oldest
+// first.
+func (s *spill) takeAll() []pendingEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := s.failed
+	for _, event := range s.inFlight {
+		events = append(events, event)
+	}
+	s.failed = nil
+	s.inFlight = make(map[int]pendingEvent)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].at.Before(events[j].at) })
+	return events
+}
+
+// trackDelivery records the event reporting entry while it is delivered in
+// the background with onDone, if spilling is enabled, and returns the
+// function to call instead of onDone.
+func (hook *bugsnagHook) trackDelivery(event pendingEvent, onDone func(error)) func(error) {
+	if hook.spill == nil {
+		return onDone
+	}
+	untrack := hook.spill.track(event)
+	return func(deliveryErr error) {
+		untrack()
+		onDone(deliveryErr)
+	}
+}
+
+// spillFailed records an event whose delivery failed, if spilling is
+// enabled.
+func (hook *bugsnagHook) spillFailed(event pendingEvent) {
+	if hook.spill != nil {
+		hook.spill.fail(event)
+	}
+}
+
+// spillPending writes the undelivered events to a new file of the spill
+// directory, if spilling is enabled and there are any.
+func (hook *bugsnagHook) spillPending() error {
+	if hook.spill == nil {
+		return nil
+	}
+	events := hook.spill.takeAll()
+	if len(events) == 0 {
+		return nil
+	}
+	f, err := ioutil.TempFile(hook.spill.dir, ".spilling-")
+	if err != nil {
+		return fmt.Errorf("logrus_bugsnag: cannot spill %d events: %w", len(events), err)
+	}
+	defer os.Remove(f.Name())
+	w := bufio.NewWriter(f)
+	for _, event := range events {
+		line, err := marshalSpilled(event)
+		if err != nil {
+			continue
+		}
+		w.Write(append(line, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("logrus_bugsnag: cannot spill %d events: %w", len(events), err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("logrus_bugsnag: cannot spill %d events: %w", len(events), err)
+	}
+	// The random suffix of the temporary file keeps the name unique.
+	suffix := strings.TrimPrefix(filepath.Base(f.Name()), ".spilling-")
+	name := fmt.Sprintf("logrus-bugsnag-%d-%s.jsonl", time.Now().UnixNano(), suffix)
+	if err := os.Rename(f.Name(), filepath.Join(hook.spill.dir, name)); err != nil {
+		return fmt.Errorf("logrus_bugsnag: cannot spill %d events: %w", len(events), err)
+	}
+	hook.stats.spilled.Add(int64(len(events)))
+	return nil
+}
+
+// marshalSpilled returns the line spilling event. The metadata is left out if
+// it cannot be encoded.
+func marshalSpilled(event pendingEvent) ([]byte, error) {
+	spilled := spilledEvent{
+		ErrorClass: event.err.TypeName(),
+		Message:    event.err.Error(),
+		Timestamp:  event.at,
+	}
+	for _, frame := range event.err.StackFrames() {
+		spilled.Stack = append(spilled.Stack, spilledFrame{File: frame.File, Line: frame.LineNumber, Method: frame.Name})
+	}
+	for _, datum := range event.rawData {
+		switch datum := datum.(type) {
+		case bugsnag.ErrorClass:
+			spilled.ErrorClass = datum.Name
+		case bugsnag.MetaData:
+			spilled.Metadata = datum
+		}
+	}
+	line, err := json.Marshal(spilled)
+	if err != nil {
+		spilled.Metadata = nil
+		line, err = json.Marshal(spilled)
+	}
+	return line, err
+}
+
+// finalized returns the event replaying e. It has no stack trace, as the
+// original one cannot be restored: its frames are in the spilled tab instead.
+func (e spilledEvent) finalized() *FinalizedEvent {
+	metadata := e.Metadata
+	if metadata == nil {
+		metadata = bugsnag.MetaData{}
+	}
+	stack := make([]string, len(e.Stack))
+	for i, frame := range e.Stack {
+		stack[i] = fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Method)
+	}
+	if metadata[spillTab] == nil {
+		// Events replayed before already have the tab.
+		mergeTab(metadata, spillTab, map[string]interface{}{
+			"stack":     stack,
+			"timestamp": e.Timestamp.UTC().Format(time.RFC3339Nano),
+		})
+	}
+	return &FinalizedEvent{
+		Error:   bugsnag_errors.New(stacklessError{errors.New(e.Message)}, 0),
+		RawData: []interface{}{metadata, bugsnag.ErrorClass{Name: e.ErrorClass}},
+	}
+}
+
+// ReplayPending sends the events spilled to the directory set with
+// WithSpillDirectory, by this hook or by a previous one, and removes their
+// files. Lines that cannot be decoded, and events older than the maximum age,
+// are skipped and counted in Stats. Events failing to be sent again are kept
+// to be spilled when the hook is closed, and their errors are joined in the
+// returned error. If ctx is done, ReplayPending stops and returns ctx.Err().
+// If the hook was created with WithDeferredConfigCheck and bugsnag is not
+// configured yet, the files are left in place and ErrBugsnagUnconfigured is
+// returned.
+func (hook *bugsnagHook) ReplayPending(ctx context.Context) error {
+	if hook.spill == nil {
+		return nil
+	}
+	if hook.sender == nil {
+		if err := hook.checkConfigured(); err != nil {
+			return err
+		}
+	}
+	files, err := filepath.Glob(filepath.Join(hook.spill.dir, spillPattern))
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		errs = append(errs, hook.replayFile(ctx, file)...)
+	}
+	return errors.Join(errs...)
+}
+
+// replayFile sends the events spilled to file, and removes it unless ctx is
+// done first.
+func (hook *bugsnagHook) replayFile(ctx context.Context, file string) []error {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(nil, len(b)+1)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return append(errs, ctx.Err())
+		}
+		var spilled spilledEvent
+		if err := json.Unmarshal(scanner.Bytes(), &spilled); err != nil || spilled.Message == "" {
+			hook.stats.spillSkipped.Add(1)
+			continue
+		}
+		if hook.spill.maxAge > 0 && time.Since(spilled.Timestamp) > hook.spill.maxAge {
+			hook.stats.spillSkipped.Add(1)
+			continue
+		}
+		event := spilled.finalized()
+		if err := hook.sendNow(ctx, event); err != nil {
+			hook.spillFailed(pendingEvent{event.Error, event.RawData, spilled.Timestamp})
+			errs = append(errs, err)
+			continue
+		}
+		hook.stats.replayed.Add(1)
+		hook.stats.sent.Add(1)
+	}
+	if err := os.Remove(file); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
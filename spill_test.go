@@ -0,0 +1,102 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vend/logrus-bugsnag/bugsnagtest"
+)
+
+func TestSpillDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Events fail to be delivered to a dead endpoint.
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+	log, hook := newAsyncLogger(t, dead.URL, WithRegistry(nil), WithSpillDirectory(dir, time.Hour))
+	log.WithField("animal", "walrus").Error("failed delivery")
+	log.Error("another failed delivery")
+	require.NoError(t, hook.Flush(context.Background()))
+	require.NoError(t, hook.Close())
+	assert.Equal(t, int64(2), hook.Stats().Spilled)
+
+	// Events still being delivered when the hook is closed are spilled too.
+	unblock := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer hanging.Close()
+	defer close(unblock)
+	log, hook = newAsyncLogger(t, hanging.URL, WithRegistry(nil), WithSpillDirectory(dir, time.Hour))
+	log.Error("in flight")
+	require.NoError(t, hook.Close())
+	assert.Equal(t, int64(1), hook.Stats().Spilled)
+
+	// Corrupt lines and events that are too old are skipped.
+	old := `{"error_class":"*errors.errorString","message":"too old","timestamp":"2001-01-01T00:00:00Z"}`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "logrus-bugsnag-1-corrupt.jsonl"),
+		[]byte("{not json\n"+old+"\n"), 0644))
+
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	_, hook = newAsyncLogger(t, c.NotifyURL(), WithRegistry(nil), WithSpillDirectory(dir, time.Hour))
+	require.NoError(t, hook.ReplayPending(context.Background()))
+	events := c.Events()
+	assert.ElementsMatch(t, []string{"failed delivery", "another failed delivery", "in flight"}, messages(events))
+	for _, event := range events {
+		assert.Equal(t, "*errors.errorString", event.Exceptions[0].ErrorClass)
+		assert.NotEmpty(t, event.Metadata["spilled"]["stack"])
+		assert.NotEmpty(t, event.Metadata["spilled"]["timestamp"])
+		if event.Exceptions[0].Message == "failed delivery" {
+			assert.Equal(t, "walrus", event.Metadata["metadata"]["animal"])
+		}
+	}
+	stats := hook.Stats()
+	assert.Equal(t, int64(3), stats.Replayed)
+	assert.Equal(t, int64(2), stats.SpillSkipped)
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestReplayPendingUnconfigured(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	spilled := fmt.Sprintf(`{"error_class":"*errors.errorString","message":"spilled","timestamp":%q}`,
+		time.Now().Format(time.RFC3339Nano))
+	file := filepath.Join(dir, "logrus-bugsnag-1-early.jsonl")
+	require.NoError(t, ioutil.WriteFile(file, []byte(spilled+"\n"), 0644))
+
+	apiKey := bugsnag.Config.APIKey
+	bugsnag.Config.APIKey = ""
+	defer func() {
+		bugsnag.Config.APIKey = apiKey
+	}()
+	hook, err := NewBugsnagHook(WithRegistry(nil), WithDeferredConfigCheck(false), WithSpillDirectory(dir, time.Hour))
+	require.NoError(t, err)
+
+	// The files are kept until bugsnag is configured.
+	assert.Equal(t, ErrBugsnagUnconfigured, hook.ReplayPending(context.Background()))
+	assert.FileExists(t, file)
+
+	c := bugsnagtest.NewCapture()
+	defer c.Close()
+	configureBugsnag(c.Endpoints())
+	require.NoError(t, hook.ReplayPending(context.Background()))
+	assert.Equal(t, "spilled", receiveEvent(t, c).Exceptions[0].Message)
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+}
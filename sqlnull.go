@@ -0,0 +1,53 @@
+package logrus_bugsnag
+
+import (
+	"database/sql"
+	"time"
+)
+
+// sqlNull is sent for NULL values: bugsnag would send nil as "<nil>".
+const sqlNull = "NULL"
+
+// sqlValue returns the value of val if it is one of the nullable types of
+// database/sql, with times formatted as RFC 3339 strings, or "NULL" if that
+// value is NULL. Other values are returned unchanged.
+func sqlValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case sql.NullString:
+		if v.Valid {
+			return v.String
+		}
+	case sql.NullInt64:
+		if v.Valid {
+			return v.Int64
+		}
+	case sql.NullInt32:
+		if v.Valid {
+			return v.Int32
+		}
+	case sql.NullInt16:
+		if v.Valid {
+			return v.Int16
+		}
+	case sql.NullByte:
+		if v.Valid {
+			return v.Byte
+		}
+	case sql.NullFloat64:
+		if v.Valid {
+			return v.Float64
+		}
+	case sql.NullBool:
+		if v.Valid {
+			return v.Bool
+		}
+	case sql.NullTime:
+		if v.Valid {
+			// bugsnag would send the struct as an empty object.
+			return v.Time.Format(time.RFC3339Nano)
+		}
+	default:
+		return val
+	}
+	return sqlNull
+}
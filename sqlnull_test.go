@@ -0,0 +1,61 @@
+package logrus_bugsnag
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSQLNullableUnwrapping(t *testing.T) {
+	created := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	fields := logrus.Fields{
+		"name":       sql.NullString{String: "walrus", Valid: true},
+		"nickname":   sql.NullString{},
+		"age":        sql.NullInt64{Int64: 42, Valid: true},
+		"weight":     sql.NullInt32{Int32: 1200, Valid: true},
+		"tusks":      sql.NullInt16{},
+		"flags":      sql.NullByte{Byte: 3, Valid: true},
+		"score":      sql.NullFloat64{Float64: 0.5, Valid: true},
+		"active":     sql.NullBool{Bool: true, Valid: true},
+		"created_at": sql.NullTime{Time: created, Valid: true},
+		"deleted_at": sql.NullTime{},
+		"animal":     "walrus",
+	}
+
+	log, _, c, teardown := newTestLogger(t, WithSQLNullableUnwrapping(true))
+	defer teardown()
+
+	log.WithFields(fields).Error(errors.New("failed"))
+	event := receiveEvent(t, c)
+	assert.Equal(t, map[string]interface{}{
+		"name":       "walrus",
+		"nickname":   "NULL",
+		"age":        float64(42),
+		"weight":     float64(1200),
+		"tusks":      "NULL",
+		"flags":      float64(3),
+		"score":      0.5,
+		"active":     true,
+		"created_at": "2019-05-01T12:00:00Z",
+		"deleted_at": "NULL",
+		"animal":     "walrus",
+	}, event.Metadata["metadata"])
+
+	// Without the option, the structs are sent as they are.
+	log, _, c, teardown = newTestLogger(t)
+	defer teardown()
+
+	log.WithField("name", sql.NullString{String: "walrus", Valid: true}).Error(errors.New("failed"))
+	event = receiveEvent(t, c)
+	assert.Equal(t, map[string]interface{}{"String": "walrus", "Valid": true}, event.Metadata["metadata"]["name"])
+}
+
+func TestSQLValue(t *testing.T) {
+	assert.Equal(t, int64(42), sqlValue(sql.NullInt64{Int64: 42, Valid: true}))
+	assert.Equal(t, "NULL", sqlValue(sql.NullInt64{}))
+	assert.Equal(t, "walrus", sqlValue("walrus"))
+}
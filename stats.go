@@ -0,0 +1,156 @@
+package logrus_bugsnag
+
+import (
+	"sync/atomic"
+	"time"
+
+	bugsnag_errors "github.com/bugsnag/bugsnag-go/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Stats counts the entries handled by a hook since it was created.
+type Stats struct {
+	// Attempted is the number of entries fired at the hook.
+	Attempted int64
+	// Sent is the number of events delivered to Bugsnag.
+	Sent int64
+	// Ignored is the number of entries deliberately not reported: context
+	// cancellations, entries with SkipField set, and entries fired while the
+	// hook was initializing, disabled or shut down, or bugsnag was not
+	// configured.
+	Ignored int64
+	// Dropped is the number of entries suppressed by the rate limit, as
+	// duplicates, or while the circuit breaker was open.
+	Dropped int64
+	// Failed is the number of events that could not be delivered, after
+	// any retries.
+	Failed int64
+	// Abandoned is the number of synchronous deliveries interrupted because
+	// the context of the entry was done. They are not retried.
+	Abandoned int64
+	// Retries is the number of delivery attempts retried after a failure,
+	// including deliveries to the mirror.
+	Retries int64
+	// Quarantined is the number of events quarantined after their delivery
+	// failed. Released is the number of them delivered by RetryQuarantined,
+	// and Evicted the number of them evicted when the quarantine was full.
+	Quarantined int64
+	Released    int64
+	Evicted     int64
+	// Spilled is the number of undelivered events written to disk when the
+	// hook was closed, with WithSpillDirectory. Replayed is the number of
+	// spilled events delivered by ReplayPending, and SpillSkipped the number
+	// of them skipped because they could not be decoded or were too old.
+	Spilled      int64
+	Replayed     int64
+	SpillSkipped int64
+	// Circuit is the state of the circuit breaker. It is always
+	// CircuitClosed without WithCircuitBreaker.
+	Circuit CircuitState
+
+	// AvgPayloadBytes and MaxPayloadBytes are the average and largest
+	// estimated size of the metadata of the events reported.
+	AvgPayloadBytes float64
+	MaxPayloadBytes int64
+	// LargestTabs are the metadata tabs with the largest average size,
+	// largest first.
+	LargestTabs []TabSize
+	// FrequentFields are the metadata fields sent with the most events, most
+	// frequent first.
+	FrequentFields []FieldCount
+}
+
+// hookStats holds the counters of Stats, updated atomically.
+type hookStats struct {
+	attempted atomic.Int64
+	sent      atomic.Int64
+	ignored   atomic.Int64
+	dropped   atomic.Int64
+	failed    atomic.Int64
+	retries   atomic.Int64
+	abandoned atomic.Int64
+
+	quarantined atomic.Int64
+	released    atomic.Int64
+	evicted     atomic.Int64
+
+	spilled      atomic.Int64
+	replayed     atomic.Int64
+	spillSkipped atomic.Int64
+
+	payload payloadStats
+}
+
+// Stats returns the hook's delivery counters and metadata aggregates. Events
+// delivered in the background are counted once their delivery completes.
+func (hook *bugsnagHook) Stats() Stats {
+	s := Stats{
+		Attempted: hook.stats.attempted.Load(),
+		Sent:      hook.stats.sent.Load(),
+		Ignored:   hook.stats.ignored.Load(),
+		Dropped:   hook.stats.dropped.Load(),
+		Failed:    hook.stats.failed.Load(),
+		Retries:   hook.stats.retries.Load(),
+		Abandoned: hook.stats.abandoned.Load(),
+
+		Quarantined: hook.stats.quarantined.Load(),
+		Released:    hook.stats.released.Load(),
+		Evicted:     hook.stats.evicted.Load(),
+
+		Spilled:      hook.stats.spilled.Load(),
+		Replayed:     hook.stats.replayed.Load(),
+		SpillSkipped: hook.stats.spillSkipped.Load(),
+	}
+	if hook.breaker != nil {
+		s.Circuit = hook.breaker.currentState()
+	}
+	hook.stats.payload.fill(&s)
+	return s
+}
+
+// delivered records the outcome of the delivery of the event reporting entry,
+// made of event and rawData.
+func (hook *bugsnagHook) delivered(entry *logrus.Entry, event *bugsnag_errors.Error, rawData []interface{}, err error) {
+	if isCanceledByCallback(err) {
+		hook.canceled()
+		return
+	}
+	if hook.breaker != nil {
+		hook.breaker.record(err, time.Now())
+	}
+	if err == nil {
+		hook.stats.sent.Add(1)
+		return
+	}
+	hook.stats.failed.Add(1)
+	hook.quarantineEvent(event, rawData, err)
+	hook.spillFailed(pendingEvent{event, rawData, entry.Time})
+	if hook.onSendError != nil {
+		hook.onSendError(err, entry)
+	}
+	if hook.errorChannel != nil {
+		select {
+		case hook.errorChannel <- ErrBugsnagSendFailed{err}:
+		default:
+			// The channel is full: drop the error rather than block.
+		}
+	}
+}
+
+// abandoned records a delivery interrupted by the context of its entry, which
+// says nothing about the health of the endpoint.
+func (hook *bugsnagHook) abandoned() {
+	hook.stats.abandoned.Add(1)
+	if hook.breaker != nil {
+		hook.breaker.release()
+	}
+}
+
+// canceled records an event canceled by the callback set with
+// WithBeforeNotify, which says nothing about the health of the endpoint.
+func (hook *bugsnagHook) canceled() {
+	hook.stats.ignored.Add(1)
+	if hook.breaker != nil {
+		hook.breaker.release()
+	}
+}
@@ -0,0 +1,158 @@
+package logrus_bugsnag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// counters returns the delivery counters of s.
+func counters(s Stats) Stats {
+	return Stats{
+		Attempted: s.Attempted,
+		Sent:      s.Sent,
+		Ignored:   s.Ignored,
+		Dropped:   s.Dropped,
+		Failed:    s.Failed,
+		Retries:   s.Retries,
+		Abandoned: s.Abandoned,
+	}
+}
+
+func TestStats(t *testing.T) {
+	log, hook, notifier := newFakeLogger(t, WithDedupWindow(time.Hour))
+
+	for i := 0; i < 3; i++ {
+		log.Error("duplicated")
+	}
+	log.WithError(context.Canceled).Error("canceled")
+	log.Error("sent")
+	assert.Equal(t, []string{"duplicated", "sent"}, messagesOf(notifier.sent()))
+	assert.Equal(t, Stats{Attempted: 5, Sent: 2, Ignored: 1, Dropped: 2}, counters(hook.Stats()))
+}
+
+func TestOnSendError(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	var mu sync.Mutex
+	var failures []string
+	onSendError := func(err error, entry *logrus.Entry) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures = append(failures, entry.Message)
+	}
+
+	// Synchronous deliveries.
+	notifier := bugsnag.New(bugsnag.Configuration{
+		APIKey:      "12345678901234567890123456789012",
+		Endpoints:   bugsnag.Endpoints{Notify: failing.URL, Sessions: failing.URL},
+		Synchronous: true,
+	})
+	hook, err := NewBugsnagHookWithNotifier(notifier, WithRegistry(nil), WithOnSendError(onSendError))
+	require.NoError(t, err)
+	fireErr := hook.Fire(logrus.NewEntry(logrus.New()).WithField("error", errors.New("sync")))
+	assert.IsType(t, ErrBugsnagSendFailed{}, fireErr)
+	assert.Equal(t, Stats{Attempted: 1, Failed: 1}, counters(hook.Stats()))
+
+	// Asynchronous deliveries.
+	log, asyncHook := newAsyncLogger(t, failing.URL, WithRegistry(nil), WithOnSendError(onSendError))
+	log.Error("async")
+	require.NoError(t, asyncHook.Flush(context.Background()))
+	assert.Equal(t, Stats{Attempted: 1, Failed: 1}, counters(asyncHook.Stats()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"", "async"}, failures)
+}
+
+func TestPayloadStats(t *testing.T) {
+	log, hook, c, teardown := newTestLogger(t, WithTabPromotion(true))
+	defer teardown()
+
+	assert.Equal(t, Stats{}, hook.Stats())
+
+	log.WithField("user_id", 1).Error("small")
+	log.WithFields(logrus.Fields{
+		"user_id": 2,
+		"request": map[string]interface{}{
+			"path":    "/orders",
+			"headers": map[string]interface{}{"accept": "application/json"},
+		},
+	}).Error("nested")
+	log.WithFields(logrus.Fields{
+		"user_id": 3,
+		"body":    strings.Repeat("x", 1000),
+	}).Error("large")
+	log.WithField("tags", []string{"a", "b"}).Error("list")
+	for i := 0; i < 4; i++ {
+		receiveEvent(t, c)
+	}
+
+	stats := hook.Stats()
+	assert.True(t, stats.MaxPayloadBytes > 1000, stats.MaxPayloadBytes)
+	assert.True(t, stats.AvgPayloadBytes > 100 && stats.AvgPayloadBytes < float64(stats.MaxPayloadBytes), stats.AvgPayloadBytes)
+	require.Len(t, stats.LargestTabs, 2)
+	assert.Equal(t, "metadata", stats.LargestTabs[0].Name)
+	assert.Equal(t, "request", stats.LargestTabs[1].Name)
+	require.Len(t, stats.FrequentFields, 5)
+	assert.Equal(t, FieldCount{Key: "user_id", Count: 3}, stats.FrequentFields[0])
+	assert.ElementsMatch(t, []string{"user_id", "path", "headers", "body", "tags"}, fieldKeys(stats.FrequentFields))
+}
+
+func TestPayloadStatsTruncated(t *testing.T) {
+	log, hook, notifier := newFakeLogger(t, WithMetadataLimits(0, 0, 1000))
+
+	log.WithFields(logrus.Fields{
+		"body":  strings.Repeat("x", 5000),
+		"small": "kept",
+	}).Error("large")
+	require.Len(t, notifier.sent(), 1)
+
+	// The stats hold the sizes of the metadata as sent, once truncated.
+	stats := hook.Stats()
+	assert.True(t, stats.MaxPayloadBytes <= 1000, stats.MaxPayloadBytes)
+	require.NotEmpty(t, stats.LargestTabs)
+	assert.Equal(t, "metadata", stats.LargestTabs[0].Name)
+	assert.True(t, stats.LargestTabs[0].AvgBytes < 1000, stats.LargestTabs[0].AvgBytes)
+}
+
+func fieldKeys(fields []FieldCount) []string {
+	var keys []string
+	for _, field := range fields {
+		keys = append(keys, field.Key)
+	}
+	return keys
+}
+
+func TestErrorChannel(t *testing.T) {
+	errs := make(chan error, 1)
+	log, _, notifier := newFakeLogger(t, WithErrorChannel(errs))
+	notifier.failWith = errors.New("bugsnag is down")
+
+	log.Error("first")
+	log.Error("second")
+	var sendErr ErrBugsnagSendFailed
+	select {
+	case err := <-errs:
+		require.True(t, errors.As(err, &sendErr))
+		assert.Equal(t, "bugsnag is down", errors.Unwrap(err).Error())
+	default:
+		t.Fatal("no error sent to the channel")
+	}
+	// The second error was dropped rather than blocking, as the channel was
+	// full.
+	assert.Empty(t, errs)
+}
@@ -0,0 +1,120 @@
+package logrus_bugsnag
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// Status describes the state of a hook.
+type Status struct {
+	// Name is the name of the hook, as set with WithName.
+	Name string
+	// Profile is the release stage whose profile was applied when the hook
+	// was created, or "" if none was.
+	Profile string
+	// Enabled is false while the hook is disabled, and Closed is true once
+	// it is shut down.
+	Enabled bool
+	Closed  bool
+	// DryRun is true if the hook logs its events instead of sending them, as
+	// set with WithDryRun.
+	DryRun bool
+	// Degraded is true while the hook is degraded after a failure of its own
+	// machinery, and Failure is that failure, as described in
+	// bugsnagHook.Failure.
+	Degraded bool
+	Failure  error
+}
+
+// Status returns the current state of the hook.
+func (hook *bugsnagHook) Status() Status {
+	hook.mu.RLock()
+	defer hook.mu.RUnlock()
+	return Status{
+		Name:     hook.name,
+		Profile:  hook.profile,
+		Enabled:  !hook.disabled,
+		Closed:   hook.closed,
+		DryRun:   hook.dryRun,
+		Degraded: hook.failure != nil,
+		Failure:  hook.failure,
+	}
+}
+
+// Config describes the settings of a hook, once its options and the profile
+// of its release stage are applied.
+type Config struct {
+	// Name is the name of the hook, and Profile the release stage whose
+	// profile was applied, or "" if none was.
+	Name    string
+	Profile string
+	// ReleaseStage is the release stage of the configuration snapshot of the
+	// hook, or "" if bugsnag is not configured yet.
+	ReleaseStage string
+	// Levels are the levels reported by the hook.
+	Levels []logrus.Level
+	// DryRun is set with WithDryRun.
+	DryRun bool
+	// DedupWindow is set with WithDedupWindow, and RateLimit and RateBurst
+	// with WithRateLimit. RateLimit is rate.Inf without a rate limit.
+	DedupWindow time.Duration
+	RateLimit   rate.Limit
+	RateBurst   int
+	// RetryAttempts and RetryDelay are set with WithRetry. RetryAttempts is
+	// 1 without retries.
+	RetryAttempts int
+	RetryDelay    time.Duration
+	// Compression is set with WithCompression, and BatchSize and
+	// BatchInterval with WithBatching. BatchSize is 1 without batching.
+	Compression   bool
+	BatchSize     int
+	BatchInterval time.Duration
+	// Sampled is true if the events are sampled, as set with WithSampler.
+	Sampled bool
+	// RedactedFields are the lower-cased substrings of the names of the
+	// redacted fields.
+	RedactedFields []string
+	// Mirrored is true if the events are also sent to the configuration set
+	// with WithMirrorConfig.
+	Mirrored bool
+}
+
+// EffectiveConfig returns the settings of the hook, once its options and the
+// profile of its release stage are applied.
+func (hook *bugsnagHook) EffectiveConfig() Config {
+	_, base := hook.notifiers()
+	config := Config{
+		Name:           hook.name,
+		Profile:        hook.profile,
+		Levels:         append([]logrus.Level(nil), hook.Levels()...),
+		DryRun:         hook.dryRun,
+		RateLimit:      rate.Inf,
+		RetryAttempts:  1,
+		Compression:    hook.compress,
+		BatchSize:      1,
+		Sampled:        hook.sampler != nil,
+		RedactedFields: append([]string(nil), hook.redactor.fields...),
+		Mirrored:       hook.mirror != nil,
+	}
+	if base != nil {
+		config.ReleaseStage = releaseStage(base)
+	}
+	if hook.limiter != nil {
+		config.DedupWindow = hook.limiter.window
+		if hook.limiter.rate != nil {
+			config.RateLimit = hook.limiter.rate.Limit()
+			config.RateBurst = hook.limiter.rate.Burst()
+		}
+	}
+	if hook.retry.maxAttempts > 1 {
+		config.RetryAttempts = hook.retry.maxAttempts
+		config.RetryDelay = hook.retry.baseDelay
+	}
+	if hook.batch != nil {
+		config.BatchSize = hook.batch.size
+		config.BatchInterval = hook.batch.interval
+	}
+	return config
+}
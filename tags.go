@@ -0,0 +1,30 @@
+package logrus_bugsnag
+
+import (
+	"strings"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+)
+
+const tagsTab = "tags"
+
+// isTagField reports whether the entry field with the given name holds a tag.
+func (hook *bugsnagHook) isTagField(key string) bool {
+	return hook.tagPrefix != "" && strings.HasPrefix(key, hook.tagPrefix) && len(key) > len(hook.tagPrefix)
+}
+
+// addTags adds the tags set by the entry's fields to their own tab, named
+// without the prefix, as Bugsnag has no tags.
+func (hook *bugsnagHook) addTags(metadata bugsnag.MetaData, entry *logrus.Entry) {
+	if hook.tagPrefix == "" {
+		return
+	}
+	for key, val := range entry.Data {
+		if !hook.isTagField(key) || !hook.fieldAllowed(key) {
+			continue
+		}
+		name := strings.TrimPrefix(key, hook.tagPrefix)
+		metadata.Add(tagsTab, name, hook.redactor.redact(name, val))
+	}
+}
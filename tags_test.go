@@ -0,0 +1,36 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTagPrefix(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithTagPrefix("tag."))
+	defer teardown()
+
+	log.WithFields(logrus.Fields{
+		"tag.environment": "eu-west-1",
+		"tag.shard":       3,
+		"tag.":            "empty",
+		"animal":          "walrus",
+	}).Error(errors.New("failed"))
+	event := receiveEvent(t, c)
+	assert.Equal(t, map[string]interface{}{"environment": "eu-west-1", "shard": float64(3)}, event.Metadata[tagsTab])
+	assert.Equal(t, map[string]interface{}{"tag.": "empty", "animal": "walrus"}, event.Metadata["metadata"])
+
+	log.Error("untagged")
+	event = receiveEvent(t, c)
+	assert.NotContains(t, event.Metadata, tagsTab)
+
+	// Without a prefix, the fields are ordinary fields.
+	log, _, c, teardown = newTestLogger(t)
+	defer teardown()
+	log.WithField("tag.environment", "eu-west-1").Error("failed")
+	event = receiveEvent(t, c)
+	assert.NotContains(t, event.Metadata, tagsTab)
+	assert.Equal(t, "eu-west-1", event.Metadata["metadata"]["tag.environment"])
+}
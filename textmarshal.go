@@ -0,0 +1,36 @@
+package logrus_bugsnag
+
+import (
+	"encoding"
+)
+
+// errorTab holds details about the reported error.
+const errorTab = "error"
+
+// marshaledError replaces the message of an error with its text form.
+type marshaledError struct {
+	error
+	text string
+}
+
+func (e marshaledError) Error() string {
+	return e.text
+}
+
+func (e marshaledError) Unwrap() error {
+	return e.error
+}
+
+// marshaledText returns the text form of err, if it implements
+// encoding.TextMarshaler and can be marshaled.
+func marshaledText(err error) (string, bool) {
+	marshaler, ok := err.(encoding.TextMarshaler)
+	if !ok {
+		return "", false
+	}
+	text, marshalErr := marshaler.MarshalText()
+	if marshalErr != nil {
+		return "", false
+	}
+	return string(text), true
+}
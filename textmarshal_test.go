@@ -0,0 +1,45 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// codedError marshals to its code, or fails to marshal without one.
+type codedError struct {
+	code string
+}
+
+func (e codedError) Error() string {
+	return "request failed"
+}
+
+func (e codedError) MarshalText() ([]byte, error) {
+	if e.code == "" {
+		return nil, errors.New("no code")
+	}
+	return []byte("request failed: " + e.code), nil
+}
+
+func TestTextMarshalerErrors(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithTextMarshalerErrors(true))
+	defer teardown()
+
+	log.WithError(codedError{"E1042"}).Error("oops")
+	event := receiveEvent(t, c)
+	assert.Equal(t, "request failed: E1042", event.Exceptions[0].Message)
+	assert.Equal(t, "logrus_bugsnag.codedError", event.Exceptions[0].ErrorClass)
+	assert.Equal(t, "request failed", event.Metadata["error"]["go_error"])
+
+	log.WithError(codedError{}).Error("oops")
+	event = receiveEvent(t, c)
+	assert.Equal(t, "request failed", event.Exceptions[0].Message)
+	assert.NotContains(t, event.Metadata, "error")
+
+	log.WithError(errors.New("plain")).Error("oops")
+	event = receiveEvent(t, c)
+	assert.Equal(t, "plain", event.Exceptions[0].Message)
+	assert.NotContains(t, event.Metadata, "error")
+}
@@ -0,0 +1,39 @@
+package logrus_bugsnag
+
+import (
+	"strings"
+	"unicode"
+)
+
+// logrusTab holds details about how the hook transformed the entry.
+const logrusTab = "logrus"
+
+// titledError replaces the message of an error with its title-cased form.
+type titledError struct {
+	error
+	title string
+}
+
+func (e titledError) Error() string {
+	return e.title
+}
+
+func (e titledError) Unwrap() error {
+	return e.error
+}
+
+// titleCase returns s with the first letter of each word in title case. The
+// other letters are unchanged, so that acronyms are preserved.
+func titleCase(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	wordStart := true
+	for _, r := range s {
+		if wordStart {
+			r = unicode.ToTitle(r)
+		}
+		wordStart = unicode.IsSpace(r)
+		b.WriteRune(r)
+	}
+	return b.String()
+}
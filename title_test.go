@@ -0,0 +1,28 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTitleCaseMessages(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithTitleCaseMessages(true))
+	defer teardown()
+
+	log.WithError(errors.New("failed to open HTTP connection")).Error("oops")
+	event := receiveEvent(t, c)
+	assert.Equal(t, "Failed To Open HTTP Connection", event.Exceptions[0].Message)
+	assert.Equal(t, "*errors.errorString", event.Exceptions[0].ErrorClass)
+	assert.Equal(t, "failed to open HTTP connection", event.Metadata["logrus"]["original_message"])
+
+	log.Error("élan  vital\tlost")
+	event = receiveEvent(t, c)
+	assert.Equal(t, "Élan  Vital\tLost", event.Exceptions[0].Message)
+
+	log.Error("Already Titled")
+	event = receiveEvent(t, c)
+	assert.Equal(t, "Already Titled", event.Exceptions[0].Message)
+	assert.NotContains(t, event.Metadata, "logrus")
+}
@@ -0,0 +1,233 @@
+package logrus_bugsnag
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	bugsnag "github.com/bugsnag/bugsnag-go"
+)
+
+const (
+	defaultMaxValueBytes    = 16 << 10
+	defaultMaxMetadataKeys  = 128
+	defaultMaxMetadataBytes = 512 << 10
+
+	// truncatedKeysField counts the fields dropped from a tab.
+	truncatedKeysField = "_truncated_keys"
+)
+
+// metadataLimits caps the metadata of events, so that oversized entries are
+// reported with partial metadata rather than rejected by Bugsnag. Limits that
+// are not positive are not enforced.
+type metadataLimits struct {
+	maxValueBytes int
+	maxKeys       int
+	maxBytes      int
+}
+
+var defaultMetadataLimits = metadataLimits{
+	maxValueBytes: defaultMaxValueBytes,
+	maxKeys:       defaultMaxMetadataKeys,
+	maxBytes:      defaultMaxMetadataBytes,
+}
+
+// apply truncates the long strings of metadata, drops the fields beyond the
+// maximum number, and then replaces its largest values until its estimated
+// size fits. Tabs are copied rather than modified, as they may be shared
+// with the entry or the hook. It returns the estimated size of each tab once
+// capped.
+func (l metadataLimits) apply(metadata bugsnag.MetaData) map[string]int {
+	if l.maxValueBytes > 0 {
+		for tab, fields := range metadata {
+			copied := false
+			for key, val := range fields {
+				truncated, ok := truncateValue(val, l.maxValueBytes)
+				if !ok {
+					continue
+				}
+				if !copied {
+					metadata[tab] = copyMap(fields)
+					copied = true
+				}
+				metadata[tab][key] = truncated
+			}
+		}
+	}
+	if l.maxKeys > 0 {
+		l.dropKeys(metadata)
+	}
+	fields, tabSizes := measure(metadata)
+	if l.maxBytes > 0 {
+		l.shrink(metadata, fields, tabSizes)
+	}
+	return tabSizes
+}
+
+// fieldSize is the estimated size of the value of a metadata field.
+type fieldSize struct {
+	tab, key string
+	size     int
+}
+
+// measure returns the estimated sizes of the values of the fields of
+// metadata, and of its tabs serialized to JSON.
+func measure(metadata bugsnag.MetaData) ([]fieldSize, map[string]int) {
+	var fields []fieldSize
+	tabSizes := make(map[string]int, len(metadata))
+	for tab, tabFields := range metadata {
+		tabSize := 2
+		for key, val := range tabFields {
+			size := estimateSize(val)
+			fields = append(fields, fieldSize{tab, key, size})
+			tabSize += len(key) + 4 + size
+		}
+		tabSizes[tab] = tabSize
+	}
+	return fields, tabSizes
+}
+
+// dropKeys keeps the first fields of metadata up to the maximum number, in
+// the order of their tabs and keys, and counts the others in each tab.
+func (l metadataLimits) dropKeys(metadata bugsnag.MetaData) {
+	count := 0
+	for _, fields := range metadata {
+		count += len(fields)
+	}
+	if count <= l.maxKeys {
+		return
+	}
+	tabs := make([]string, 0, len(metadata))
+	for tab := range metadata {
+		tabs = append(tabs, tab)
+	}
+	sort.Strings(tabs)
+	kept := 0
+	for _, tab := range tabs {
+		fields := copyMap(metadata[tab])
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		dropped := 0
+		for _, key := range keys {
+			if kept < l.maxKeys {
+				kept++
+				continue
+			}
+			delete(fields, key)
+			dropped++
+		}
+		if dropped > 0 {
+			fields[truncatedKeysField] = dropped
+			metadata[tab] = fields
+		}
+	}
+}
+
+// shrink replaces the largest values of metadata with a marker until its
+// estimated size fits, given the sizes of its fields and tabs as returned by
+// measure. tabSizes is updated with the markers.
+func (l metadataLimits) shrink(metadata bugsnag.MetaData, fields []fieldSize, tabSizes map[string]int) {
+	total := payloadSize(tabSizes)
+	if total <= l.maxBytes {
+		return
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].size > fields[j].size })
+	copied := make(map[string]bool)
+	for _, f := range fields {
+		if total <= l.maxBytes {
+			return
+		}
+		if !copied[f.tab] {
+			metadata[f.tab] = copyMap(metadata[f.tab])
+			copied[f.tab] = true
+		}
+		marker := fmt.Sprintf("(truncated, %d bytes)", f.size)
+		metadata[f.tab][f.key] = marker
+		saved := f.size - estimateSize(marker)
+		tabSizes[f.tab] -= saved
+		total -= saved
+	}
+}
+
+// truncateValue returns val with its strings longer than max bytes truncated,
+// and whether any was. Byte slices, errors and fmt.Stringers are truncated as
+// the string they are reported as. Maps and slices are copied rather than
+// modified.
+func truncateValue(val interface{}, max int) (interface{}, bool) {
+	switch v := val.(type) {
+	case string:
+		if len(v) > max {
+			return truncateString(v, max), true
+		}
+	case []byte:
+		if len(v) > max {
+			return truncateString(string(v), max), true
+		}
+	case error:
+		if message := v.Error(); len(message) > max {
+			return truncateString(message, max), true
+		}
+	case fmt.Stringer:
+		if s := v.String(); len(s) > max {
+			return truncateString(s, max), true
+		}
+	case []string:
+		var copied []string
+		for i, elem := range v {
+			if len(elem) <= max {
+				continue
+			}
+			if copied == nil {
+				copied = append([]string(nil), v...)
+			}
+			copied[i] = truncateString(elem, max)
+		}
+		if copied != nil {
+			return copied, true
+		}
+	case map[string]interface{}:
+		var copied map[string]interface{}
+		for key, elem := range v {
+			truncated, ok := truncateValue(elem, max)
+			if !ok {
+				continue
+			}
+			if copied == nil {
+				copied = copyMap(v)
+			}
+			copied[key] = truncated
+		}
+		if copied != nil {
+			return copied, true
+		}
+	case []interface{}:
+		var copied []interface{}
+		for i, elem := range v {
+			truncated, ok := truncateValue(elem, max)
+			if !ok {
+				continue
+			}
+			if copied == nil {
+				copied = append([]interface{}(nil), v...)
+			}
+			copied[i] = truncated
+		}
+		if copied != nil {
+			return copied, true
+		}
+	}
+	return val, false
+}
+
+// truncateString returns the first max bytes of s, without splitting a rune,
+// followed by the length of s.
+func truncateString(s string, max int) string {
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...(truncated, %d bytes)", s[:cut], len(s))
+}
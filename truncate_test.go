@@ -0,0 +1,116 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataLimits(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t)
+	defer teardown()
+
+	long := strings.Repeat("é", 20000)
+	nested := map[string]interface{}{"body": long}
+	log.WithFields(logrus.Fields{
+		"payload": long,
+		"request": nested,
+		"short":   "kept",
+	}).Error(errors.New("failed"))
+	event := receiveEvent(t, c)
+	payload := event.Metadata["metadata"]["payload"].(string)
+	assert.True(t, strings.HasSuffix(payload, "...(truncated, 40000 bytes)"), payload)
+	assert.Len(t, payload, defaultMaxValueBytes+len("...(truncated, 40000 bytes)"))
+	body := event.Metadata["metadata"]["request"].(map[string]interface{})["body"].(string)
+	assert.True(t, strings.HasSuffix(body, "...(truncated, 40000 bytes)"), body)
+	assert.Equal(t, "kept", event.Metadata["metadata"]["short"])
+	// The fields of the entry are not modified.
+	assert.Equal(t, long, nested["body"])
+
+	fields := logrus.Fields{}
+	for i := 0; i < 200; i++ {
+		fields[fmt.Sprintf("field%03d", i)] = i
+	}
+	log.WithFields(fields).Error(errors.New("failed"))
+	event = receiveEvent(t, c)
+	assert.Len(t, event.Metadata["metadata"], defaultMaxMetadataKeys+1)
+	assert.Contains(t, event.Metadata["metadata"], "field000")
+	assert.NotContains(t, event.Metadata["metadata"], "field199")
+	assert.Equal(t, float64(200-defaultMaxMetadataKeys), event.Metadata["metadata"][truncatedKeysField])
+}
+
+func TestWithMetadataLimits(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithMetadataLimits(0, 0, 1000))
+	defer teardown()
+
+	large := strings.Repeat("a", 2000)
+	log.WithFields(logrus.Fields{
+		"large": large,
+		"small": "kept",
+	}).Error(errors.New("failed"))
+	event := receiveEvent(t, c)
+	assert.Equal(t, "(truncated, 2002 bytes)", event.Metadata["metadata"]["large"])
+	assert.Equal(t, "kept", event.Metadata["metadata"]["small"])
+
+	// Without limits, metadata is sent as is.
+	log, _, c, teardown = newTestLogger(t, WithMetadataLimits(0, 0, 0))
+	defer teardown()
+	long := strings.Repeat("a", 20000)
+	log.WithField("payload", long).Error(errors.New("failed"))
+	event = receiveEvent(t, c)
+	assert.Equal(t, long, event.Metadata["metadata"]["payload"])
+}
+
+// hostname is a fmt.Stringer.
+type hostname string
+
+func (h hostname) String() string {
+	return string(h)
+}
+
+func TestTruncateValue(t *testing.T) {
+	long := strings.Repeat("a", 10)
+	truncated := "aaaa...(truncated, 10 bytes)"
+
+	t.Run("string slice", func(t *testing.T) {
+		val := []string{"kept", long}
+		got, ok := truncateValue(val, 4)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"kept", truncated}, got)
+		// The slice of the entry is not modified.
+		assert.Equal(t, long, val[1])
+
+		_, ok = truncateValue([]string{"kept"}, 4)
+		assert.False(t, ok)
+	})
+
+	t.Run("byte slice", func(t *testing.T) {
+		got, ok := truncateValue([]byte(long), 4)
+		assert.True(t, ok)
+		assert.Equal(t, truncated, got)
+
+		got, ok = truncateValue([]byte("kept"), 4)
+		assert.False(t, ok)
+		assert.Equal(t, []byte("kept"), got)
+	})
+
+	t.Run("stringer", func(t *testing.T) {
+		got, ok := truncateValue(hostname(long), 4)
+		assert.True(t, ok)
+		assert.Equal(t, truncated, got)
+
+		got, ok = truncateValue(hostname("kept"), 4)
+		assert.False(t, ok)
+		assert.Equal(t, hostname("kept"), got)
+	})
+}
+
+func TestTruncateString(t *testing.T) {
+	assert.Equal(t, "ab...(truncated, 5 bytes)", truncateString("abcde", 2))
+	// Runes are not split.
+	assert.Equal(t, "a...(truncated, 5 bytes)", truncateString("aéé", 2))
+}
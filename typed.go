@@ -0,0 +1,61 @@
+package logrus_bugsnag
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// jsonValue returns val as decoded from its JSON encoding, if it implements
+// json.Marshaler or is a struct or a pointer to a struct, so that the types of
+// its fields are those of encoding/json: time.Time as RFC 3339 strings, []byte
+// as base64 strings and structs as maps. Integers are decoded as int64, and
+// other numbers as float64. Errors, and values that fail to encode, are
+// returned unchanged.
+func jsonValue(val interface{}) interface{} {
+	if _, ok := val.(error); ok {
+		return val
+	}
+	if _, ok := val.(json.Marshaler); !ok {
+		v := reflect.ValueOf(val)
+		if v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return val
+		}
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		return val
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return val
+	}
+	return fromJSONNumbers(decoded)
+}
+
+// fromJSONNumbers replaces the json.Number values of v, as decoded with
+// UseNumber, with int64 or float64 values.
+func fromJSONNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = fromJSONNumbers(val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = fromJSONNumbers(val)
+		}
+	}
+	return v
+}
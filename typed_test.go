@@ -0,0 +1,55 @@
+package logrus_bugsnag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type order struct {
+	ID       int       `json:"id"`
+	Total    float64   `json:"total"`
+	Paid     bool      `json:"paid"`
+	PlacedAt time.Time `json:"placed_at"`
+	Receipt  []byte    `json:"receipt"`
+	Customer struct {
+		Name string `json:"name"`
+	} `json:"customer"`
+}
+
+func TestTypedFields(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithTypedFields(true))
+	defer teardown()
+
+	o := order{ID: 1042, Total: 12.5, Paid: true, Receipt: []byte("ok")}
+	o.PlacedAt = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	o.Customer.Name = "Ada"
+	log.WithField("order", &o).WithField("size", 1001).Error("oops")
+
+	event := receiveEvent(t, c)
+	received, ok := event.Metadata["metadata"]["order"].(map[string]interface{})
+	require.True(t, ok, "the struct was not sent as an object")
+	assert.Equal(t, map[string]interface{}{
+		"id":        float64(1042),
+		"total":     12.5,
+		"paid":      true,
+		"placed_at": "2020-01-02T03:04:05Z",
+		"receipt":   "b2s=",
+		"customer":  map[string]interface{}{"name": "Ada"},
+	}, received)
+	assert.Equal(t, float64(1001), event.Metadata["metadata"]["size"])
+}
+
+func TestJSONValue(t *testing.T) {
+	type id struct {
+		ID uint64 `json:"id"`
+	}
+	assert.Equal(t, map[string]interface{}{"id": int64(1<<62 + 1)}, jsonValue(id{1<<62 + 1}))
+	assert.Equal(t, "2020-01-02T03:04:05Z", jsonValue(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)))
+	assert.Equal(t, 42, jsonValue(42))
+	assert.Equal(t, (*id)(nil), jsonValue((*id)(nil)))
+	err := &validationError{}
+	assert.Equal(t, err, jsonValue(err))
+}
@@ -0,0 +1,36 @@
+package logrus_bugsnag
+
+import (
+	bugsnag "github.com/bugsnag/bugsnag-go"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultUnhandledLevels are the levels of the entries reported as unhandled
+// errors: the entries logged by Panic, which is about to crash the goroutine.
+var defaultUnhandledLevels = []logrus.Level{logrus.PanicLevel}
+
+// unhandledState returns the handled state of the events reporting entries at
+// level, or false if they are handled errors. Bugsnag counts unhandled errors
+// against the stability score of the release.
+func (hook *bugsnagHook) unhandledState(level logrus.Level) (bugsnag.HandledState, bool) {
+	for _, l := range hook.unhandledLevels {
+		if l != level {
+			continue
+		}
+		state := bugsnag.HandledState{
+			SeverityReason:   bugsnag.SeverityReasonUnhandledError,
+			OriginalSeverity: bugsnag.SeverityError,
+			Unhandled:        true,
+		}
+		switch {
+		case level == logrus.PanicLevel:
+			state.SeverityReason = bugsnag.SeverityReasonUnhandledPanic
+		case level == logrus.WarnLevel:
+			state.OriginalSeverity = bugsnag.SeverityWarning
+		case level > logrus.WarnLevel:
+			state.OriginalSeverity = bugsnag.SeverityInfo
+		}
+		return state, true
+	}
+	return bugsnag.HandledState{}, false
+}
@@ -0,0 +1,44 @@
+package logrus_bugsnag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnhandledPanics(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithPanicValue("panic_value"))
+	defer teardown()
+
+	log.WithError(errors.New("handled")).Error("failed")
+	event := receiveEvent(t, c)
+	assert.False(t, event.Unhandled)
+	assert.Equal(t, "handledError", event.SeverityReason.Type)
+	assert.Equal(t, "warning", event.Severity)
+
+	assert.Panics(t, func() {
+		log.WithField("panic_value", errors.New("nil map")).Panic("recovered")
+	})
+	event = receiveEvent(t, c)
+	assert.True(t, event.Unhandled)
+	assert.Equal(t, "unhandledPanic", event.SeverityReason.Type)
+	assert.Equal(t, "error", event.Severity)
+	assert.Equal(t, "nil map", event.Metadata["panic"]["value"])
+}
+
+func TestWithUnhandledLevels(t *testing.T) {
+	log, _, c, teardown := newTestLogger(t, WithUnhandledLevels(logrus.ErrorLevel))
+	defer teardown()
+
+	log.Error("unhandled")
+	event := receiveEvent(t, c)
+	assert.True(t, event.Unhandled)
+	assert.Equal(t, "unhandledError", event.SeverityReason.Type)
+
+	assert.Panics(t, func() { log.Panic("handled") })
+	event = receiveEvent(t, c)
+	assert.False(t, event.Unhandled)
+	assert.Equal(t, "handledError", event.SeverityReason.Type)
+}
@@ -0,0 +1,33 @@
+package logrus_bugsnag
+
+import (
+	"encoding/hex"
+	"reflect"
+)
+
+// normalizeUUID returns the canonical hyphenated form of val if it is a
+// 16-byte array, such as a github.com/google/uuid UUID, which would otherwise
+// be serialized as a list of numbers. Other values are returned unchanged.
+func normalizeUUID(val interface{}) interface{} {
+	if val == nil {
+		return val
+	}
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Array || v.Len() != 16 || v.Type().Elem().Kind() != reflect.Uint8 {
+		return val
+	}
+	var b [16]byte
+	reflect.Copy(reflect.ValueOf(b[:]), v)
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf[:])
+}
@@ -0,0 +1,30 @@
+package logrus_bugsnag
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// testUUID has the same underlying type as github.com/google/uuid UUIDs.
+type testUUID [16]byte
+
+func TestUUIDNormalization(t *testing.T) {
+	id := testUUID{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+	fields := logrus.Fields{
+		"request_id": id,
+		"raw_id":     [16]byte(id),
+		"short":      [4]byte{1, 2, 3, 4},
+		"name":       "walrus",
+	}
+
+	log, _, c, teardown := newTestLogger(t, WithUUIDNormalization(true))
+	defer teardown()
+	log.WithFields(fields).Error("normalized")
+	metadata := receiveEvent(t, c).Metadata["metadata"]
+	assert.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", metadata["request_id"])
+	assert.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", metadata["raw_id"])
+	assert.Equal(t, []interface{}{float64(1), float64(2), float64(3), float64(4)}, metadata["short"])
+	assert.Equal(t, "walrus", metadata["name"])
+}